@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+func printResultBaseImages(w io.Writer, results internal.TrivyResults) error {
+	tw := newLightTableWriter()
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 4, WidthMax: 80},
+		{Number: 5, WidthMax: 60},
+	})
+	tw.AppendHeader(table.Row{"OS Family", "Version", "EOL", "Image Digest", "Projects"})
+	eoslCount := 0
+	for _, baseImage := range results.GroupByBaseImage() {
+		eol := ""
+		if baseImage.EOSL {
+			eol = "EOL"
+			eoslCount++
+		}
+		tw.AppendRow(table.Row{
+			baseImage.Family,
+			baseImage.Version,
+			eol,
+			baseImage.Digest,
+			strings.Join(baseImage.Projects, "\n"),
+		})
+		tw.AppendSeparator()
+	}
+	tw.AppendFooter(table.Row{"", "", eoslCount, "", "base images are end of life"})
+
+	_, err := fmt.Fprintln(w, tw.Render())
+	return err
+}
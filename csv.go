@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+var csvColumns = map[string]func(internal.Finding) string{
+	"group":     func(f internal.Finding) string { return f.Group },
+	"instance":  func(f internal.Finding) string { return f.Instance },
+	"label":     func(f internal.Finding) string { return f.Label },
+	"project":   func(f internal.Finding) string { return f.Project },
+	"namespace": func(f internal.Finding) string { return f.Namespace },
+	"ref":       func(f internal.Finding) string { return f.Ref },
+	"target":    func(f internal.Finding) string { return f.Target },
+	"class":     func(f internal.Finding) string { return f.Class },
+	"id":        func(f internal.Finding) string { return f.ID },
+	"package":   func(f internal.Finding) string { return f.Package },
+	"installed": func(f internal.Finding) string { return f.Installed },
+	"fixed":     func(f internal.Finding) string { return f.Fixed },
+	"severity":  func(f internal.Finding) string { return f.Severity },
+	"cvss":      func(f internal.Finding) string { return formatCVSS(f.CVSS) },
+	"title":     func(f internal.Finding) string { return f.Title },
+	"ignored":   func(f internal.Finding) string { return strconv.FormatBool(f.Ignored) },
+}
+
+var defaultCsvColumns = []string{"project", "namespace", "ref", "target", "class", "id", "package", "installed", "fixed", "severity", "cvss", "title", "ignored"}
+
+// optionalCsvColumns are only written if they are given with --columns. The
+// group column is added by default with --group-by.
+var optionalCsvColumns = []string{"group", "instance", "label"}
+
+func printResultCsv(w io.Writer, results internal.TrivyResults, separator rune) error {
+	columns, err := csvColumnNames(viper.GetStringSlice(COLUMNS))
+	if err != nil {
+		return err
+	}
+	if len(viper.GetStringSlice(COLUMNS)) == 0 && isGrouped(results) {
+		columns = append([]string{"group"}, columns...)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = separator
+	if err := csvWriter.Write(columns); err != nil {
+		return err
+	}
+	for _, finding := range results.Findings() {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = csvColumns[column](finding)
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func csvColumnNames(columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return defaultCsvColumns, nil
+	}
+	names := []string{}
+	for _, column := range columns {
+		name := strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q, valid columns are %s", column, strings.Join(append(optionalCsvColumns, defaultCsvColumns...), ","))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func formatCVSS(score float64) string {
+	if score == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
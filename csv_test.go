@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultCsv(t *testing.T) {
+	tests := []struct {
+		name      string
+		columns   []string
+		groupBy   string
+		separator rune
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "default columns",
+			separator: ',',
+			want: []string{
+				"project,namespace,ref,target,class,id,package,installed,fixed,severity,cvss,title,ignored",
+				"app,group/team,main,app (alpine 3.19.1),vuln,CVE-2024-0001,openssl,3.0.0,3.0.1,CRITICAL,,openssl: a title which is longer than fifty characters and is cut,false",
+				"app,group/team,main,app (alpine 3.19.1),vuln,CVE-2024-0002,busybox-binsh,1.36.1,,LOW,,busybox: <script> | pipe,true",
+				"app,group/team,main,Dockerfile,misconfig,DS002,,,,HIGH,,Image user should not be 'root',false",
+				"app,group/team,main,.env,secret,aws-access-key-id,,,,CRITICAL,,AWS Access Key ID,false",
+				"app,group/team,main,package-lock.json,license,GPL-3.0,readline,,,HIGH,,restricted,false",
+				"app,group/team,main,package-lock.json,license,MIT,left-pad,,,LOW,,notice,false",
+			},
+		},
+		{
+			name:      "group column with group by",
+			columns:   []string{},
+			groupBy:   internal.GroupByNamespace,
+			separator: ',',
+			want:      []string{"group,project,namespace,ref,target,class,id,package,installed,fixed,severity,cvss,title,ignored"},
+		},
+		{
+			name:      "selected columns",
+			columns:   []string{"Instance", " label", "id"},
+			groupBy:   internal.GroupByNamespace,
+			separator: '\t',
+			want:      []string{"instance\tlabel\tid", "gitlab.com\tplatform\tCVE-2024-0001"},
+		},
+		{
+			name:      "unknown column",
+			columns:   []string{"project", "cve"},
+			separator: ',',
+			wantErr:   `unknown column "cve", valid columns are group,instance,label,project,`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfig(t, map[string]any{COLUMNS: tt.columns})
+			results := loadResults(t)
+			require.NoError(t, results.Order(internal.SortByName, tt.groupBy))
+
+			buf := &bytes.Buffer{}
+			err := printResultCsv(buf, results, tt.separator)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			require.GreaterOrEqual(t, len(lines), len(tt.want))
+			assert.Equal(t, tt.want, lines[:len(tt.want)])
+		})
+	}
+}
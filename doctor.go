@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+const doctorCommand = "doctor"
+
+// doDoctor checks the access to all instances and exits with 1 if a check
+// failed.
+func doDoctor(instances []internal.Instance, targetsFile string) {
+	failed := false
+	for i, instance := range instances {
+		checks := doctorChecks(instance, targetsFile)
+		failed = failed || internal.Failed(checks)
+		if i > 0 {
+			fmt.Println()
+		}
+		name := instance.Host
+		if instance.Name != "" {
+			name = fmt.Sprintf("%s (%s)", instance.Name, instance.Host)
+		}
+		printChecks(os.Stdout, name, checks)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// doctorChecks runs the checks of an instance. An invalid configuration is
+// reported as failed check.
+func doctorChecks(instance internal.Instance, targetsFile string) []internal.Check {
+	git, err := newGitLabClient(instance)
+	if err == nil {
+		var s *internal.Scan
+		if s, err = newScan(instance, git, targetsFile); err == nil {
+			return internal.Doctor{Git: git, Scan: s, TokenType: instance.Credentials.TokenType}.Run()
+		}
+	}
+	return []internal.Check{{
+		Name:    "configuration",
+		Status:  internal.CheckFail,
+		Message: err.Error(),
+		Hint:    "Fix the flags or the config file",
+	}}
+}
+
+// printChecks prints the checks with the hints of warnings and failures and a
+// summary.
+func printChecks(w io.Writer, name string, checks []internal.Check) {
+	fmt.Fprintf(w, "Checking %s\n", name)
+	count := map[string]int{}
+	for _, check := range checks {
+		count[check.Status]++
+		fmt.Fprintf(w, "  [%-4s] %s: %s\n", check.Status, check.Name, check.Message)
+		if check.Hint != "" {
+			fmt.Fprintf(w, "         -> %s\n", check.Hint)
+		}
+	}
+	fmt.Fprintf(w, "%d ok, %d warnings, %d failed\n", count[internal.CheckOK], count[internal.CheckWarn], count[internal.CheckFail])
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []internal.Check
+		want   string
+	}{
+		{
+			name:   "ok",
+			checks: []internal.Check{{Name: "connectivity", Status: internal.CheckOK, Message: "reached GitLab 17.0"}},
+			want: `Checking gitlab.com
+  [ok  ] connectivity: reached GitLab 17.0
+1 ok, 0 warnings, 0 failed
+`,
+		},
+		{
+			name: "hints",
+			checks: []internal.Check{
+				{Name: "token", Status: internal.CheckOK, Message: "authenticated"},
+				{Name: "expiry", Status: internal.CheckWarn, Message: "expires in 3 days", Hint: "Rotate the token"},
+				{Name: "scopes", Status: internal.CheckFail, Message: "missing read_api", Hint: "Add the read_api scope"},
+			},
+			want: `Checking gitlab.com
+  [ok  ] token: authenticated
+  [warn] expiry: expires in 3 days
+         -> Rotate the token
+  [fail] scopes: missing read_api
+         -> Add the read_api scope
+1 ok, 1 warnings, 1 failed
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			printChecks(buf, "gitlab.com", tt.checks)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestDoctorChecks(t *testing.T) {
+	instance := internal.Instance{Host: "https://gitlab.com", Credentials: internal.Credentials{TokenType: internal.TokenTypePersonal, Token: "secret"}}
+	setConfig(t, map[string]any{INCLUDE: []string{"path=("}})
+
+	checks := doctorChecks(instance, "")
+	if assert.Len(t, checks, 1) {
+		assert.Equal(t, "configuration", checks[0].Name)
+		assert.Equal(t, internal.CheckFail, checks[0].Status)
+		assert.Contains(t, checks[0].Message, "invalid project rules")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	logger "github.com/sirupsen/logrus"
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/xanzy/go-gitlab"
+)
+
+// doDryRun lists all projects of the groups and whether they would be
+// scanned. Only the project lists are requested from GitLab.
+func doDryRun() {
+	projs := make([][]*gitlab.Project, len(scans))
+	for i, scan := range scans {
+		var err error
+		if projs[i], err = scan.ListProjects(); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	if err := printDryRun(os.Stdout, scans, projs); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// printDryRun prints the projects listed by each scan. The instance column is
+// only printed if several instances are configured.
+func printDryRun(w io.Writer, scans []*internal.Scan, projs [][]*gitlab.Project) error {
+	instances := false
+	for _, scan := range scans {
+		instances = instances || scan.Instance != ""
+	}
+	ew := &errWriter{w: w}
+	tw := tabwriter.NewWriter(ew, 0, 0, 2, ' ', 0)
+	if instances {
+		fmt.Fprint(tw, "INSTANCE\t")
+	}
+	fmt.Fprintln(tw, "ACTION\tPROJECT\tVISIBILITY\tTOPICS\tLAST ACTIVITY\tREASON")
+	selected, total := 0, 0
+	for i, scan := range scans {
+		for _, proj := range projs[i] {
+			total++
+			action := "skip"
+			ok, reason := scan.Selects(proj)
+			if ok {
+				action = "scan"
+				selected++
+			}
+			lastActivity := ""
+			if proj.LastActivityAt != nil {
+				lastActivity = proj.LastActivityAt.Format("2006-01-02")
+			}
+			if instances {
+				fmt.Fprintf(tw, "%s\t", scan.Instance)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", action, proj.PathWithNamespace, proj.Visibility, strings.Join(proj.Topics, ","), lastActivity, reason)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(ew, "\n%d of %d projects would be scanned\n", selected, total)
+	return ew.err
+}
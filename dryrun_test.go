@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestPrintDryRun(t *testing.T) {
+	rules, err := internal.NewProjectRules([]string{"path=^group/team/"}, []string{"visibility=public"})
+	require.NoError(t, err)
+	lastActivity := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	projs := []*gitlab.Project{
+		{PathWithNamespace: "group/team/app", NameWithNamespace: "group / team / app", Visibility: gitlab.PrivateVisibility, Topics: []string{"docker", "go"}, LastActivityAt: &lastActivity},
+		{PathWithNamespace: "group/team/site", NameWithNamespace: "group / team / site", Visibility: gitlab.PublicVisibility},
+		{PathWithNamespace: "group/other/lib", NameWithNamespace: "group / other / lib", Visibility: gitlab.InternalVisibility},
+		{PathWithNamespace: "group/team/legacy", NameWithNamespace: "group / team / legacy", Visibility: gitlab.PrivateVisibility},
+	}
+
+	tests := []struct {
+		name  string
+		scans []*internal.Scan
+		projs [][]*gitlab.Project
+		want  string
+	}{
+		{
+			name:  "single instance",
+			scans: []*internal.Scan{{Rules: rules, Filter: regexp.MustCompile("app|site|lib")}},
+			projs: [][]*gitlab.Project{projs},
+			want: `ACTION  PROJECT            VISIBILITY  TOPICS     LAST ACTIVITY  REASON
+scan    group/team/app     private     docker,go  2024-05-01     
+skip    group/team/site    public                                excluded by visibility=public
+skip    group/other/lib    internal                              no include rule matches
+skip    group/team/legacy  private                               filtered out
+
+1 of 4 projects would be scanned
+`,
+		},
+		{
+			name:  "several instances",
+			scans: []*internal.Scan{{Instance: "gitlab.com"}, {Instance: "internal", Rules: rules}},
+			projs: [][]*gitlab.Project{projs[:1], projs[1:3]},
+			want: `INSTANCE    ACTION  PROJECT          VISIBILITY  TOPICS     LAST ACTIVITY  REASON
+gitlab.com  scan    group/team/app   private     docker,go  2024-05-01     
+internal    skip    group/team/site  public                                excluded by visibility=public
+internal    skip    group/other/lib  internal                              no include rule matches
+
+1 of 3 projects would be scanned
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, printDryRun(buf, tt.scans, tt.projs))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+// htmlTopEntries defines how many projects and vulnerabilities are shown in
+// the dashboard of the html report.
+const htmlTopEntries = 10
+
+type htmlReport struct {
+	Version   string
+	Generated string
+	Summary   internal.Summary
+	Results   internal.TrivyResults
+	CSS       template.CSS
+	JS        template.JS
+}
+
+var htmlFuncs = template.FuncMap{
+	"severityRank": func(severity string) int {
+		return internal.SeverityRank(strings.ToUpper(severity))
+	},
+	"severityBadge": func(severity string) template.HTML {
+		class := strings.ToLower(severity)
+		if internal.SeverityRank(strings.ToUpper(severity)) == 0 {
+			class = "unknown"
+		}
+		return template.HTML(fmt.Sprintf(`<span class="badge %s">%s</span>`, class, template.HTMLEscapeString(severity)))
+	},
+	"cvss": func(vuln types.DetectedVulnerability) string {
+		return formatCVSS(internal.CVSSScore(vuln))
+	},
+	"misconfLocation": internal.MisconfLocation,
+}
+
+func printResultHtml(w io.Writer, results internal.TrivyResults) error {
+	tmpl, err := template.New("report.html.tmpl").Funcs(htmlFuncs).ParseFS(templatesFS, "templates/report.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse html template: %w", err)
+	}
+	css, err := templatesFS.ReadFile("templates/report.css")
+	if err != nil {
+		return fmt.Errorf("failed to read html stylesheet: %w", err)
+	}
+	js, err := templatesFS.ReadFile("templates/report.js")
+	if err != nil {
+		return fmt.Errorf("failed to read html script: %w", err)
+	}
+
+	return tmpl.Execute(w, htmlReport{
+		Version:   version,
+		Generated: time.Now().Format(time.RFC1123),
+		Summary:   results.Summarize(htmlTopEntries),
+		Results:   results,
+		CSS:       template.CSS(css),
+		JS:        template.JS(js),
+	})
+}
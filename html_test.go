@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultHtml(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupBy  string
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "report",
+			contains: []string{
+				`<p class="meta">Generated `,
+				`<div class="card critical"><div class="count">1</div>Critical</div>`,
+				`<td data-sort="4"><span class="badge critical">CRITICAL</span></td>`,
+				`busybox: &lt;script&gt; | pipe`,
+				`Source: job scan_oci_image_trivy (#123)`,
+			},
+			excludes: []string{`<script> | pipe`, `<h3 class="group">`},
+		},
+		{
+			name:     "grouped",
+			groupBy:  internal.GroupByNamespace,
+			contains: []string{`<h3 class="group">group/team</h3>`, `<h3 class="group">other</h3>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := loadResults(t)
+			require.NoError(t, results.Order(internal.SortByName, tt.groupBy))
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, printResultHtml(buf, results))
+			for _, s := range tt.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
+
+func TestHtmlFuncs(t *testing.T) {
+	badge := htmlFuncs["severityBadge"].(func(string) template.HTML)
+	assert.Equal(t, template.HTML(`<span class="badge high">HIGH</span>`), badge("HIGH"))
+	assert.Equal(t, template.HTML(`<span class="badge unknown">&lt;b&gt;</span>`), badge("<b>"))
+	rank := htmlFuncs["severityRank"].(func(string) int)
+	assert.Equal(t, 4, rank("critical"))
+	assert.Equal(t, 0, rank("UNKNOWN"))
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"sort"
+)
+
+// BaseImage groups all projects which are built on the same OS family,
+// OS version and image digest.
+type BaseImage struct {
+	Family   string
+	Version  string
+	EOSL     bool
+	Digest   string
+	Projects []string
+}
+
+// GroupByBaseImage rolls up the report metadata of all results by OS family,
+// OS version and image digest. Reports without OS information are skipped.
+// The returned list is sorted by family, version and digest.
+func (t TrivyResults) GroupByBaseImage() []*BaseImage {
+	groups := map[string]*BaseImage{}
+	for _, result := range t {
+		for _, report := range result.Reports {
+			osInfo := report.Metadata.OS
+			if osInfo == nil {
+				continue
+			}
+			digest := imageDigest(report)
+			key := string(osInfo.Family) + "|" + osInfo.Name + "|" + digest
+			grp, ok := groups[key]
+			if !ok {
+				grp = &BaseImage{
+					Family:  string(osInfo.Family),
+					Version: osInfo.Name,
+					Digest:  digest,
+				}
+				groups[key] = grp
+			}
+			grp.EOSL = grp.EOSL || osInfo.Eosl
+			if !contains(grp.Projects, result.ProjName) {
+				grp.Projects = append(grp.Projects, result.ProjName)
+			}
+		}
+	}
+
+	baseImages := []*BaseImage{}
+	for _, grp := range groups {
+		sort.Strings(grp.Projects)
+		baseImages = append(baseImages, grp)
+	}
+	sort.Slice(baseImages, func(i, j int) bool {
+		a, b := baseImages[i], baseImages[j]
+		if a.Family != b.Family {
+			return a.Family < b.Family
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Digest < b.Digest
+	})
+	return baseImages
+}
+
+func (r *trivy) hasEOSL() bool {
+	for _, report := range r.Reports {
+		if report.Metadata.OS != nil && report.Metadata.OS.Eosl {
+			return true
+		}
+	}
+	return false
+}
+
+func imageDigest(report Report) string {
+	if len(report.Metadata.RepoDigests) > 0 {
+		return report.Metadata.RepoDigests[0]
+	}
+	return report.Metadata.ImageID
+}
+
+func contains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"testing"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByBaseImage(t *testing.T) {
+	alpine := &ftypes.OS{Family: "alpine", Name: "3.7.1", Eosl: true}
+	debian := &ftypes.OS{Family: "debian", Name: "12.5"}
+
+	trivyResults := TrivyResults{
+		&trivy{
+			ProjName: "proj2",
+			Reports: []Report{
+				{Metadata: types.Metadata{OS: alpine, RepoDigests: []string{"app@sha256:aaaa"}}},
+				{Metadata: types.Metadata{OS: debian, ImageID: "sha256:cccc"}},
+			},
+		},
+		&trivy{
+			ProjName: "proj1",
+			Reports: []Report{
+				{Metadata: types.Metadata{OS: alpine, RepoDigests: []string{"app@sha256:aaaa"}}},
+			},
+		},
+		&trivy{
+			ProjName: "proj3",
+			Reports: []Report{
+				{Metadata: types.Metadata{}},
+			},
+		},
+	}
+
+	baseImages := trivyResults.GroupByBaseImage()
+	assert.Len(t, baseImages, 2)
+	assert.Equal(t, "alpine", baseImages[0].Family)
+	assert.Equal(t, "3.7.1", baseImages[0].Version)
+	assert.Equal(t, "app@sha256:aaaa", baseImages[0].Digest)
+	assert.True(t, baseImages[0].EOSL)
+	assert.Equal(t, []string{"proj1", "proj2"}, baseImages[0].Projects)
+	assert.Equal(t, "debian", baseImages[1].Family)
+	assert.Equal(t, "sha256:cccc", baseImages[1].Digest)
+	assert.False(t, baseImages[1].EOSL)
+	assert.Equal(t, []string{"proj2"}, baseImages[1].Projects)
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	retryablehttp "github.com/hashicorp/go-retryablehttp"
+	"github.com/spf13/viper"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	TokenTypePersonal = "token"
+	TokenTypeJob      = "job-token"
+	TokenTypeOAuth    = "oauth"
+)
+
+var tokenTypes = []string{TokenTypePersonal, TokenTypeJob, TokenTypeOAuth}
+
+// Credentials define how trivyops authenticates at GitLab. The token is
+// either a personal, group or project access token, a CI job token or an
+// OAuth token. A token file is re-read whenever it changes, so rotated tokens
+// are used without restarting the daemon.
+type Credentials struct {
+	TokenType string `mapstructure:"tokenType"`
+	Token     string `mapstructure:"token"`
+	TokenFile string `mapstructure:"tokenFile"`
+}
+
+// CredentialsFromConfig returns the credentials of GITLAB_TOKEN,
+// GITLAB_TOKEN_TYPE and GITLAB_TOKEN_FILE. Without token and token file
+// CI_JOB_TOKEN is used as job token if it's set. The job token is never used
+// for configured instances, because it must not be sent to other hosts.
+func CredentialsFromConfig() Credentials {
+	c := Credentials{
+		TokenType: viper.GetString(GITLAB_TOKEN_TYPE),
+		Token:     viper.GetString(GTILAB_TOKEN),
+		TokenFile: viper.GetString(GITLAB_TOKEN_FILE),
+	}
+	tokenType := strings.ToLower(strings.TrimSpace(c.TokenType))
+	if c.Token == "" && c.TokenFile == "" && (tokenType == "" || tokenType == TokenTypeJob) {
+		if jobToken := viper.GetString(CI_JOB_TOKEN); jobToken != "" {
+			c.Token = jobToken
+			c.TokenType = TokenTypeJob
+		}
+	}
+	return c
+}
+
+// Resolve validates the credentials and fills in the defaults.
+func (c Credentials) Resolve() (Credentials, error) {
+	c.TokenType = strings.ToLower(strings.TrimSpace(c.TokenType))
+	if c.TokenType == "" {
+		c.TokenType = TokenTypePersonal
+	}
+	if !contains(tokenTypes, c.TokenType) {
+		return c, fmt.Errorf("unknown token type %q, use one of [%s]", c.TokenType, strings.Join(tokenTypes, ", "))
+	}
+	if c.Token != "" && c.TokenFile != "" {
+		return c, fmt.Errorf("token and token file can't be used together")
+	}
+	if c.Token == "" && c.TokenFile == "" {
+		return c, fmt.Errorf("no token set, use %s, %s or %s", GTILAB_TOKEN, GITLAB_TOKEN_FILE, CI_JOB_TOKEN)
+	}
+	return c, nil
+}
+
+// NewGitLabClient creates the GitLab client with the constructor matching the
+// token type.
+func (c Credentials) NewGitLabClient(options ...gitlab.ClientOptionFunc) (*gitlab.Client, error) {
+	token := c.Token
+	if c.TokenFile != "" {
+		source := &fileToken{path: c.TokenFile}
+		var err error
+		if token, err = source.Token(); err != nil {
+			return nil, err
+		}
+		options = append(options, gitlab.WithRequestOptions(func(req *retryablehttp.Request) error {
+			token, err := source.Token()
+			if err != nil {
+				return err
+			}
+			c.setHeader(req, token)
+			return nil
+		}))
+	}
+
+	switch c.TokenType {
+	case TokenTypeJob:
+		return gitlab.NewJobClient(token, options...)
+	case TokenTypeOAuth:
+		return gitlab.NewOAuthClient(token, options...)
+	default:
+		return gitlab.NewClient(token, options...)
+	}
+}
+
+// setHeader sets the authentication header of the token type. go-gitlab
+// doesn't overwrite it with the token the client was created with.
+func (c Credentials) setHeader(req *retryablehttp.Request, token string) {
+	switch c.TokenType {
+	case TokenTypeJob:
+		req.Header.Set("JOB-TOKEN", token)
+	case TokenTypeOAuth:
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+}
+
+// fileToken reads a token from a file and re-reads it if the file was
+// modified e.g. by a rotated Kubernetes secret.
+type fileToken struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+func (f *fileToken) Token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+	f.token, f.modTime = token, info.ModTime()
+	return f.token, nil
+}
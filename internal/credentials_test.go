@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestCredentialsResolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		jobToken    string
+		credentials Credentials
+		resolved    Credentials
+		err         string
+	}{
+		{name: "personal access token", credentials: Credentials{Token: "glpat"}, resolved: Credentials{TokenType: TokenTypePersonal, Token: "glpat"}},
+		{name: "job token", credentials: Credentials{TokenType: "job-token", Token: "job"}, resolved: Credentials{TokenType: TokenTypeJob, Token: "job"}},
+		{name: "oauth token file", credentials: Credentials{TokenType: " OAuth", TokenFile: "/var/run/secrets/token"}, resolved: Credentials{TokenType: TokenTypeOAuth, TokenFile: "/var/run/secrets/token"}},
+		{name: "unknown type", credentials: Credentials{TokenType: "basic", Token: "glpat"}, err: `unknown token type "basic", use one of [token, job-token, oauth]`},
+		{name: "token and file", credentials: Credentials{Token: "glpat", TokenFile: "token"}, err: "token and token file can't be used together"},
+		{name: "no token", err: "no token set, use GITLAB_TOKEN, GITLAB_TOKEN_FILE or CI_JOB_TOKEN"},
+		{name: "job token isn't used", jobToken: "job", err: "no token set, use GITLAB_TOKEN, GITLAB_TOKEN_FILE or CI_JOB_TOKEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CI_JOB_TOKEN, tt.jobToken)
+			resolved, err := tt.credentials.Resolve()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.resolved, resolved)
+		})
+	}
+}
+
+func TestCredentialsFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		tokenType   string
+		jobToken    string
+		credentials Credentials
+	}{
+		{name: "token", token: "glpat", credentials: Credentials{Token: "glpat"}},
+		{name: "token wins over job token", token: "glpat", jobToken: "job", credentials: Credentials{Token: "glpat"}},
+		{name: "job token", jobToken: "job", credentials: Credentials{TokenType: TokenTypeJob, Token: "job"}},
+		{name: "job token isn't used for oauth", tokenType: TokenTypeOAuth, jobToken: "job", credentials: Credentials{TokenType: TokenTypeOAuth}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(GTILAB_TOKEN, tt.token)
+			t.Setenv(GITLAB_TOKEN_TYPE, tt.tokenType)
+			t.Setenv(CI_JOB_TOKEN, tt.jobToken)
+			assert.Equal(t, tt.credentials, CredentialsFromConfig())
+		})
+	}
+}
+
+func TestCredentialsNewGitLabClient(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	request := func(t *testing.T, credentials Credentials) http.Header {
+		git, err := credentials.NewGitLabClient(gitlab.WithBaseURL(server.URL))
+		require.NoError(t, err)
+		_, _, err = git.Projects.GetProject(1, nil)
+		require.NoError(t, err)
+		return <-headers
+	}
+
+	t.Run("token types", func(t *testing.T) {
+		assert.Equal(t, "glpat", request(t, Credentials{TokenType: TokenTypePersonal, Token: "glpat"}).Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "job", request(t, Credentials{TokenType: TokenTypeJob, Token: "job"}).Get("JOB-TOKEN"))
+		assert.Equal(t, "Bearer oauth", request(t, Credentials{TokenType: TokenTypeOAuth, Token: "oauth"}).Get("Authorization"))
+	})
+
+	t.Run("rotated token file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(file, []byte("first\n"), 0o600))
+		credentials := Credentials{TokenType: TokenTypeOAuth, TokenFile: file}
+		git, err := credentials.NewGitLabClient(gitlab.WithBaseURL(server.URL))
+		require.NoError(t, err)
+
+		_, _, err = git.Projects.GetProject(1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer first"}, (<-headers).Values("Authorization"))
+
+		require.NoError(t, os.WriteFile(file, []byte("second\n"), 0o600))
+		rotated := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(file, rotated, rotated))
+		_, _, err = git.Projects.GetProject(1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer second"}, (<-headers).Values("Authorization"))
+	})
+
+	t.Run("missing token file", func(t *testing.T) {
+		git, err := Credentials{TokenFile: filepath.Join(t.TempDir(), "token")}.NewGitLabClient(gitlab.WithBaseURL(server.URL))
+		assert.Nil(t, git)
+		assert.ErrorContains(t, err, "failed to read token file")
+	})
+
+	t.Run("empty token file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(file, []byte("\n"), 0o600))
+		git, err := Credentials{TokenFile: file}.NewGitLabClient(gitlab.WithBaseURL(server.URL))
+		assert.Nil(t, git)
+		assert.EqualError(t, err, "token file "+file+" is empty")
+	})
+}
@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+const (
+	// tokenExpiryWarning is the time before the token expiry when the doctor
+	// starts to warn.
+	tokenExpiryWarning = 14 * 24 * time.Hour
+	// doctorSamples is the number of projects which are checked for a trivy
+	// job until one is found.
+	doctorSamples = 5
+)
+
+// readScopes are the token scopes which allow to read projects and download
+// job artifacts.
+var readScopes = []string{"read_api", "api"}
+
+// Check is the result of a single doctor check. The hint tells how to fix a
+// warning or failure.
+type Check struct {
+	Name    string
+	Status  string
+	Message string
+	Hint    string
+}
+
+// Doctor checks if the configured GitLab instance can be scanned: whether it
+// is reachable, the token is valid, has the needed scopes and doesn't expire
+// soon, the targets are accessible and the artifact of a sample project can
+// be downloaded.
+type Doctor struct {
+	Git       *gitlab.Client
+	Scan      *Scan
+	TokenType string
+}
+
+// Run runs all checks. Checks depending on a failed check are skipped.
+func (d Doctor) Run() []Check {
+	checks, ok := d.checkToken()
+	if !ok {
+		return checks
+	}
+	for _, target := range d.Scan.Targets {
+		checks = append(checks, d.checkTarget(target))
+	}
+	return append(checks, d.checkArtifacts())
+}
+
+// checkToken checks the connectivity and the token with a request matching
+// the token type. Scopes and expiry can only be checked for access tokens.
+func (d Doctor) checkToken() ([]Check, bool) {
+	var resp *gitlab.Response
+	var err error
+	var pat *gitlab.PersonalAccessToken
+	identity := ""
+	switch d.TokenType {
+	case TokenTypeJob:
+		var job *gitlab.Job
+		if job, resp, err = d.Git.Jobs.GetJobTokensJob(&gitlab.GetJobTokensJobOptions{}); err == nil {
+			identity = fmt.Sprintf("job token of job %d", job.ID)
+		}
+	case TokenTypeOAuth:
+		var user *gitlab.User
+		if user, resp, err = d.Git.Users.CurrentUser(); err == nil {
+			identity = fmt.Sprintf("oauth token of user %s", user.Username)
+		}
+	default:
+		if pat, resp, err = d.Git.PersonalAccessTokens.GetSinglePersonalAccessToken(); err == nil {
+			identity = fmt.Sprintf("access token %s", pat.Name)
+		}
+	}
+
+	if resp == nil {
+		return []Check{{
+			Name:    "connectivity",
+			Status:  CheckFail,
+			Message: fmt.Sprintf("can't reach %s: %v", d.Git.BaseURL(), err),
+			Hint:    "Check GITLAB_HOST, the proxy (--proxy, HTTPS_PROXY) and the TLS options (--ca-file, --client-cert, --insecure-skip-verify)",
+		}}, false
+	}
+	checks := []Check{{Name: "connectivity", Status: CheckOK, Message: fmt.Sprintf("reached %s", d.Git.BaseURL())}}
+	if err != nil {
+		return append(checks, Check{
+			Name:    "token",
+			Status:  CheckFail,
+			Message: fmt.Sprintf("token was rejected: %v", err),
+			Hint:    fmt.Sprintf("Check %s or %s and %s, the token may be invalid, expired or revoked", GTILAB_TOKEN, GITLAB_TOKEN_FILE, GITLAB_TOKEN_TYPE),
+		}), false
+	}
+	if pat != nil && (pat.Revoked || !pat.Active) {
+		return append(checks, Check{
+			Name:    "token",
+			Status:  CheckFail,
+			Message: fmt.Sprintf("%s is not active", identity),
+			Hint:    "Create a new access token with the read_api scope",
+		}), false
+	}
+	checks = append(checks, Check{Name: "token", Status: CheckOK, Message: fmt.Sprintf("authenticated with %s", identity)})
+	if pat == nil {
+		return checks, true
+	}
+	return append(checks, checkScopes(pat), checkExpiry(pat)), true
+}
+
+func checkScopes(pat *gitlab.PersonalAccessToken) Check {
+	for _, scope := range pat.Scopes {
+		if contains(readScopes, scope) {
+			return Check{Name: "scopes", Status: CheckOK, Message: fmt.Sprintf("token has the %s scope", scope)}
+		}
+	}
+	return Check{
+		Name:    "scopes",
+		Status:  CheckFail,
+		Message: fmt.Sprintf("token has none of the scopes %v", readScopes),
+		Hint:    "Create a token with the read_api scope, it's needed to list projects and download artifacts",
+	}
+}
+
+func checkExpiry(pat *gitlab.PersonalAccessToken) Check {
+	if pat.ExpiresAt == nil {
+		return Check{Name: "expiry", Status: CheckOK, Message: "token doesn't expire"}
+	}
+	expiresAt := time.Time(*pat.ExpiresAt)
+	left := expiresAt.Sub(now())
+	switch {
+	case left < 0:
+		return Check{
+			Name:    "expiry",
+			Status:  CheckFail,
+			Message: fmt.Sprintf("token expired on %s", pat.ExpiresAt),
+			Hint:    "Rotate the token",
+		}
+	case left < tokenExpiryWarning:
+		return Check{
+			Name:    "expiry",
+			Status:  CheckWarn,
+			Message: fmt.Sprintf("token expires on %s", pat.ExpiresAt),
+			Hint:    fmt.Sprintf("Rotate the token before %s", pat.ExpiresAt),
+		}
+	}
+	return Check{Name: "expiry", Status: CheckOK, Message: fmt.Sprintf("token expires on %s", pat.ExpiresAt)}
+}
+
+// checkTarget checks if the group or project is visible to the token.
+func (d Doctor) checkTarget(target Target) Check {
+	check := Check{Name: target.String()}
+	var err error
+	switch target.Kind {
+	case TargetGroup:
+		var group *gitlab.Group
+		if group, _, err = d.Git.Groups.GetGroup(target.ID, &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)}); err == nil {
+			check.Message = fmt.Sprintf("can access group %s", group.FullPath)
+		}
+	default:
+		var proj *gitlab.Project
+		if proj, _, err = d.Git.Projects.GetProject(target.ID, nil); err == nil {
+			check.Message = fmt.Sprintf("can access project %s", proj.PathWithNamespace)
+		}
+	}
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("can't access %s: %v", target, err)
+		check.Hint = fmt.Sprintf("Check the %s ID or path and grant the token user at least the Reporter role", target.Kind)
+		return check
+	}
+	check.Status = CheckOK
+	return check
+}
+
+// checkArtifacts lists the projects and downloads the trivy result of the
+// first selected project with a trivy job.
+func (d Doctor) checkArtifacts() Check {
+	check := Check{Name: "artifacts"}
+	projs, err := d.Scan.ListProjects()
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("failed to list projects: %v", err)
+		check.Hint = "Check the groups and projects and the project list options"
+		return check
+	}
+	selected := d.Scan.SelectProjects(projs)
+	if len(selected) == 0 {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("none of %d projects is selected", len(projs))
+		check.Hint = "Check --filter, --include and --exclude with --dry-run"
+		return check
+	}
+
+	sampled := 0
+	for _, proj := range selected {
+		if sampled == doctorSamples {
+			break
+		}
+		sampled++
+		jobs, err := d.Scan.getTrivyJob(d.Scan.JobName, proj.ID)
+		if err != nil && statusCode(err) != http.StatusNotFound {
+			check.Status = CheckFail
+			check.Message = fmt.Sprintf("failed to list the jobs of the latest pipeline of %s: %v", proj.PathWithNamespace, err)
+			check.Hint = permissionHint(err, "Check that the token user has at least the Reporter role", "Grant the token user at least the Reporter role to read pipelines and jobs")
+			return check
+		}
+		if len(jobs) == 0 {
+			// a 404 means that the project has no pipeline
+			continue
+		}
+		result, err := d.Scan.getTrivyResult(d.Scan.ArtifactFileName, jobs[0])
+		switch {
+		case err != nil:
+			check.Status = CheckFail
+			check.Message = fmt.Sprintf("failed to read %s of %s: %v", d.Scan.ArtifactFileName, proj.PathWithNamespace, err)
+			check.Hint = permissionHint(err, fmt.Sprintf("Check %s and that the token user has at least the Reporter role", ARTIFACT), "Grant the token user at least the Reporter role to download job artifacts")
+		case result == nil:
+			check.Status = CheckWarn
+			check.Message = fmt.Sprintf("job %s of %s has no artifacts", jobs[0].Name, proj.PathWithNamespace)
+			check.Hint = "Check that the job keeps the trivy result as artifact and it's not expired"
+		default:
+			check.Status = CheckOK
+			check.Message = fmt.Sprintf("downloaded %s of %s", d.Scan.ArtifactFileName, proj.PathWithNamespace)
+		}
+		return check
+	}
+	check.Status = CheckWarn
+	check.Message = fmt.Sprintf("no %s job found in the latest pipeline of %d sampled projects", d.Scan.JobName, sampled)
+	check.Hint = fmt.Sprintf("Check %s", JOB_NAME)
+	return check
+}
+
+// permissionHint returns the forbidden hint if GitLab denied the request and
+// the hint otherwise.
+func permissionHint(err error, hint, forbidden string) string {
+	if statusCode(err) == http.StatusForbidden {
+		return forbidden
+	}
+	return hint
+}
+
+// statusCode returns the http status of a GitLab error response or 0.
+// go-gitlab returns ErrNotFound instead of the response for 404.
+func statusCode(err error) int {
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	var errResp *gitlab.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode
+	}
+	return 0
+}
+
+// Failed returns true if one of the checks failed.
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestDoctor(t *testing.T) {
+	artifacts, err := os.ReadFile("../test/result.zip")
+	require.NoError(t, err)
+	now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	t.Run("all checks pass", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["read_api"],"expires_at":"2025-01-01"}`)
+		mux.HandleFunc("GET /api/v4/projects/1/jobs/100/artifacts", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(artifacts)
+		})
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token ok", "scopes ok", "expiry ok", "project 1 ok", "artifacts ok"}, statuses(checks))
+		assert.Equal(t, "downloaded trivy-result.json of group/app", checks[5].Message)
+		assert.False(t, Failed(checks))
+	})
+
+	t.Run("token expires soon and misses scope", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["read_repository"],"expires_at":"2024-06-10"}`)
+		mux.HandleFunc("GET /api/v4/projects/1/jobs/100/artifacts", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"403 Forbidden"}`)
+		})
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token ok", "scopes fail", "expiry warn", "project 1 ok", "artifacts fail"}, statuses(checks))
+		assert.Equal(t, "Rotate the token before 2024-06-10", checks[3].Hint)
+		assert.Equal(t, "Grant the token user at least the Reporter role to download job artifacts", checks[5].Hint)
+		assert.True(t, Failed(checks))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["api"],"expires_at":"2024-05-01"}`)
+		checks := runDoctor(t, mux, TokenTypePersonal, "not-there.json")
+		assert.Equal(t, "expiry fail", statuses(checks)[3])
+	})
+
+	t.Run("missing artifacts", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["api"]}`)
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token ok", "scopes ok", "expiry ok", "project 1 ok", "artifacts warn"}, statuses(checks))
+	})
+
+	t.Run("pipelines forbidden", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["api"]}`)
+		mux.HandleFunc("GET /api/v4/projects/1/pipelines/latest", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"403 Forbidden"}`)
+		})
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token ok", "scopes ok", "expiry ok", "project 1 ok", "artifacts fail"}, statuses(checks))
+		assert.Equal(t, "Grant the token user at least the Reporter role to read pipelines and jobs", checks[5].Hint)
+	})
+
+	t.Run("no pipeline", func(t *testing.T) {
+		mux := fakeGitLab(t, `{"name":"trivyops","active":true,"scopes":["api"]}`)
+		mux.HandleFunc("GET /api/v4/projects/1/pipelines/latest", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not found"}`)
+		})
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, "artifacts warn", statuses(checks)[5])
+		assert.Equal(t, "no scan_oci_image_trivy job found in the latest pipeline of 1 sampled projects", checks[5].Message)
+	})
+
+	t.Run("job token", func(t *testing.T) {
+		mux := fakeGitLab(t, "")
+		mux.HandleFunc("GET /api/v4/job", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "secret", r.Header.Get("JOB-TOKEN"))
+			fmt.Fprint(w, `{"id":42}`)
+		})
+		checks := runDoctor(t, mux, TokenTypeJob, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token ok", "project 1 ok", "artifacts warn"}, statuses(checks))
+		assert.Equal(t, "authenticated with job token of job 42", checks[1].Message)
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+		})
+		checks := runDoctor(t, mux, TokenTypePersonal, "trivy-result.json")
+		assert.Equal(t, []string{"connectivity ok", "token fail"}, statuses(checks))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NewServeMux())
+		server.Close()
+		git, err := gitlab.NewClient("secret", gitlab.WithBaseURL(server.URL), gitlab.WithoutRetries())
+		require.NoError(t, err)
+		checks := Doctor{Git: git, Scan: &Scan{}}.Run()
+		assert.Equal(t, []string{"connectivity fail"}, statuses(checks))
+	})
+}
+
+// fakeGitLab serves the access token and project 1 whose latest pipeline has a
+// trivy job.
+func fakeGitLab(t *testing.T, token string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/personal_access_tokens/self", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, token)
+	})
+	mux.HandleFunc("GET /api/v4/projects/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":1,"path_with_namespace":"group/app","name_with_namespace":"group / app"}`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}/pipelines/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":10}`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/pipelines/10/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":100,"name":"scan_oci_image_trivy","project":{"id":1}}]`)
+	})
+	return mux
+}
+
+func runDoctor(t *testing.T, mux *http.ServeMux, tokenType, artifact string) []Check {
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	git, err := Credentials{TokenType: tokenType, Token: "secret"}.NewGitLabClient(gitlab.WithBaseURL(server.URL), gitlab.WithoutRetries())
+	require.NoError(t, err)
+	client := &GitLabClient{
+		GroupsClient:    git.Groups,
+		ProjectsClient:  git.Projects,
+		JobsClient:      git.Jobs,
+		PipelinesClient: git.Pipelines,
+		RepositoryFiles: git.RepositoryFiles,
+	}
+	s, err := InitScanner("", "scan_oci_image_trivy", artifact, "", client)
+	require.NoError(t, err)
+	s.Targets = []Target{{Kind: TargetProject, ID: "1", Label: "1"}}
+	return Doctor{Git: git, Scan: s, TokenType: tokenType}.Run()
+}
+
+func statuses(checks []Check) []string {
+	result := []string{}
+	for _, check := range checks {
+		result = append(result, check.Name+" "+check.Status)
+	}
+	return result
+}
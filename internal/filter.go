@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+var findingClasses = []string{ClassVulnerability, ClassMisconfiguration, ClassSecret, ClassLicense}
+
+// FindingFilter reduces the findings of the scanned projects. It's applied by
+// the scanner before the findings are counted so that all outputs, metrics
+// and gates see the same findings.
+type FindingFilter struct {
+	Severities  []string
+	Classes     []string
+	FixableOnly bool
+	ID          *regexp.Regexp
+	Package     *regexp.Regexp
+}
+
+// NewFindingFilter validates the filter values and returns the filter. Empty
+// values don't filter anything.
+func NewFindingFilter(severities, classes []string, fixableOnly bool, idRegex, pkgRegex string) (*FindingFilter, error) {
+	filter := &FindingFilter{FixableOnly: fixableOnly}
+	for _, severity := range severities {
+		severity = strings.ToUpper(strings.TrimSpace(severity))
+		if severity != "UNKNOWN" && SeverityRank(severity) == 0 {
+			return nil, fmt.Errorf("unknown severity %q, use one of [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]", severity)
+		}
+		filter.Severities = append(filter.Severities, severity)
+	}
+	for _, class := range classes {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if !contains(findingClasses, class) {
+			return nil, fmt.Errorf("unknown class %q, use one of [%s]", class, strings.Join(findingClasses, ", "))
+		}
+		filter.Classes = append(filter.Classes, class)
+	}
+	var err error
+	if idRegex != "" {
+		if filter.ID, err = regexp.Compile(idRegex); err != nil {
+			return nil, fmt.Errorf("%s is not a valid regex: %v", idRegex, err)
+		}
+	}
+	if pkgRegex != "" {
+		if filter.Package, err = regexp.Compile(pkgRegex); err != nil {
+			return nil, fmt.Errorf("%s is not a valid regex: %v", pkgRegex, err)
+		}
+	}
+	return filter, nil
+}
+
+// apply removes all findings of the project which don't match the filter.
+// FixableOnly only removes vulnerabilities without fixed version, use Classes
+// to get vulnerabilities only. Findings without package (misconfigurations
+// and secrets) never match a Package regex.
+func (f *FindingFilter) apply(r *trivy) {
+	if f == nil {
+		return
+	}
+	for i := range r.ReportResult {
+		tgt := &r.ReportResult[i]
+		tgt.Vulnerabilities = filterSlice(tgt.Vulnerabilities, func(v types.DetectedVulnerability) bool {
+			return f.match(ClassVulnerability, v.Severity, v.PkgName, v.VulnerabilityID) &&
+				(!f.FixableOnly || v.FixedVersion != "")
+		})
+		tgt.Misconfigurations = filterSlice(tgt.Misconfigurations, func(m types.DetectedMisconfiguration) bool {
+			return f.match(ClassMisconfiguration, m.Severity, "", m.ID, m.AVDID)
+		})
+		tgt.Secrets = filterSlice(tgt.Secrets, func(s types.DetectedSecret) bool {
+			return f.match(ClassSecret, s.Severity, "", s.RuleID)
+		})
+		tgt.Licenses = filterSlice(tgt.Licenses, func(l types.DetectedLicense) bool {
+			return f.match(ClassLicense, l.Severity, l.PkgName, l.Name)
+		})
+	}
+}
+
+func (f *FindingFilter) match(class, severity, pkg string, ids ...string) bool {
+	if len(f.Classes) > 0 && !contains(f.Classes, class) {
+		return false
+	}
+	if len(f.Severities) > 0 && !contains(f.Severities, strings.ToUpper(severity)) {
+		return false
+	}
+	if f.Package != nil && (pkg == "" || !f.Package.MatchString(pkg)) {
+		return false
+	}
+	if f.ID != nil {
+		for _, id := range ids {
+			if id != "" && f.ID.MatchString(id) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func filterSlice[T any](list []T, keep func(T) bool) []T {
+	if list == nil {
+		return nil
+	}
+	filtered := []T{}
+	for _, item := range list {
+		if keep(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"testing"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindingFilter(t *testing.T) {
+	newResult := func() *trivy {
+		return &trivy{ProjName: "app", ReportResult: Results{
+			{Result: types.Result{
+				Target: "app (debian 12.5)",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{VulnerabilityID: "CVE-2024-0001", PkgName: "openssl", FixedVersion: "3.0.1", Vulnerability: dbtypes.Vulnerability{Severity: "CRITICAL"}},
+					{VulnerabilityID: "CVE-2024-0002", PkgName: "curl", Vulnerability: dbtypes.Vulnerability{Severity: "HIGH"}},
+					{VulnerabilityID: "GHSA-1234", PkgName: "openssl-libs", FixedVersion: "1.1", Vulnerability: dbtypes.Vulnerability{Severity: "LOW"}},
+				},
+			}},
+			{Result: types.Result{
+				Target:            "Dockerfile",
+				Misconfigurations: []types.DetectedMisconfiguration{{ID: "DS002", AVDID: "AVD-DS-0002", Severity: "HIGH", Status: types.MisconfStatusFailure}},
+				Secrets:           []types.DetectedSecret{{RuleID: "github-pat", Severity: "CRITICAL"}},
+				Licenses:          []types.DetectedLicense{{Name: "GPL-3.0", PkgName: "openssl", Severity: "HIGH"}},
+			}},
+		}}
+	}
+	ids := func(r *trivy) []string {
+		ids := []string{}
+		for _, f := range r.findings() {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name        string
+		severities  []string
+		classes     []string
+		fixableOnly bool
+		idRegex     string
+		pkgRegex    string
+		ids         []string
+	}{
+		{name: "no filter", ids: []string{"CVE-2024-0001", "CVE-2024-0002", "GHSA-1234", "DS002", "github-pat", "GPL-3.0"}},
+		{name: "severity", severities: []string{"critical", " HIGH"}, ids: []string{"CVE-2024-0001", "CVE-2024-0002", "DS002", "github-pat", "GPL-3.0"}},
+		{name: "class", classes: []string{"secret", "misconfig"}, ids: []string{"DS002", "github-pat"}},
+		{name: "fixable only", fixableOnly: true, ids: []string{"CVE-2024-0001", "GHSA-1234", "DS002", "github-pat", "GPL-3.0"}},
+		{name: "fixable vulnerabilities", classes: []string{"vuln"}, fixableOnly: true, ids: []string{"CVE-2024-0001", "GHSA-1234"}},
+		{name: "id regex", idRegex: "^CVE-|^AVD-", ids: []string{"CVE-2024-0001", "CVE-2024-0002", "DS002"}},
+		{name: "package regex", pkgRegex: "^openssl", ids: []string{"CVE-2024-0001", "GHSA-1234", "GPL-3.0"}},
+		{name: "package regex matching empty", pkgRegex: ".*", ids: []string{"CVE-2024-0001", "CVE-2024-0002", "GHSA-1234", "GPL-3.0"}},
+		{name: "combined", severities: []string{"CRITICAL", "LOW"}, pkgRegex: "openssl", ids: []string{"CVE-2024-0001", "GHSA-1234"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFindingFilter(tt.severities, tt.classes, tt.fixableOnly, tt.idRegex, tt.pkgRegex)
+			require.NoError(t, err)
+			result := newResult()
+			filter.apply(result)
+			assert.Equal(t, tt.ids, ids(result))
+		})
+	}
+
+	t.Run("counts see the filtered findings", func(t *testing.T) {
+		filter, err := NewFindingFilter([]string{"CRITICAL"}, nil, false, "", "")
+		require.NoError(t, err)
+		result := newResult()
+		filter.apply(result)
+		result.check()
+		assert.Equal(t, 2, result.Vulnerabilities.Count)
+		assert.Equal(t, 1, result.Vulnerabilities.Critical)
+		assert.Equal(t, 0, result.Vulnerabilities.High)
+		assert.Equal(t, 1, result.Vulnerabilities.Secrets)
+		assert.Empty(t, result.Licenses)
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		var filter *FindingFilter
+		result := newResult()
+		filter.apply(result)
+		assert.Len(t, ids(result), 6)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := NewFindingFilter([]string{"severe"}, nil, false, "", "")
+		assert.EqualError(t, err, `unknown severity "SEVERE", use one of [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]`)
+		_, err = NewFindingFilter(nil, []string{"bug"}, false, "", "")
+		assert.EqualError(t, err, `unknown class "bug", use one of [vuln, misconfig, secret, license]`)
+		_, err = NewFindingFilter(nil, nil, false, "(", "")
+		assert.Error(t, err)
+		_, err = NewFindingFilter(nil, nil, false, "", "[")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const (
+	ClassVulnerability    = "vuln"
+	ClassMisconfiguration = "misconfig"
+	ClassSecret           = "secret"
+	ClassLicense          = "license"
+)
+
+// Finding is a single flat finding of any class. It is used by all outputs
+// which need one row per finding.
+type Finding struct {
+	Group     string
+	Instance  string
+	Label     string
+	Project   string
+	Namespace string
+	Ref       string
+	Target    string
+	Class     string
+	ID        string
+	Package   string
+	Installed string
+	Fixed     string
+	Severity  string
+	CVSS      float64
+	Title     string
+	Ignored   bool
+}
+
+// Findings flattens all vulnerabilities, failed misconfigurations, secrets and
+// licenses of all projects into one list.
+func (t TrivyResults) Findings() []Finding {
+	findings := []Finding{}
+	for _, result := range t {
+		findings = append(findings, result.findings()...)
+	}
+	return findings
+}
+
+func (r *trivy) findings() []Finding {
+	findings := []Finding{}
+	for _, tgt := range r.ReportResult {
+		base := Finding{
+			Group:     r.Group,
+			Instance:  r.Instance,
+			Label:     r.Label,
+			Project:   r.ProjName,
+			Namespace: r.ProjNamespace,
+			Target:    tgt.Target,
+		}
+		if tgt.Provenance != nil {
+			base.Ref = tgt.Provenance.Ref
+		}
+		for _, v := range tgt.Vulnerabilities {
+			f := base
+			f.Class = ClassVulnerability
+			f.ID = v.VulnerabilityID
+			f.Package = v.PkgName
+			f.Installed = v.InstalledVersion
+			f.Fixed = v.FixedVersion
+			f.Severity = v.Severity
+			f.CVSS = CVSSScore(v)
+			f.Title = v.Title
+			f.Ignored = r.IsIgnored(v.VulnerabilityID)
+			findings = append(findings, f)
+		}
+		for _, m := range tgt.Misconfigurations {
+			// findings have no status, so only failed checks are findings
+			if m.Status == types.MisconfStatusPassed || m.Status == types.MisconfStatusException {
+				continue
+			}
+			f := base
+			f.Class = ClassMisconfiguration
+			f.ID = m.ID
+			f.Severity = m.Severity
+			f.Title = m.Title
+			f.Ignored = r.IsIgnored(m.ID) || r.IsIgnored(m.AVDID)
+			findings = append(findings, f)
+		}
+		for _, s := range tgt.Secrets {
+			f := base
+			f.Class = ClassSecret
+			f.ID = s.RuleID
+			f.Severity = s.Severity
+			f.Title = s.Title
+			f.Ignored = r.IsIgnored(s.RuleID)
+			findings = append(findings, f)
+		}
+		for _, l := range tgt.Licenses {
+			f := base
+			f.Class = ClassLicense
+			f.ID = l.Name
+			f.Package = l.PkgName
+			f.Severity = l.Severity
+			f.Title = string(l.Category)
+			f.Ignored = r.IsIgnored(l.Name)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// IsIgnored returns true if the id is listed in the .trivyignore of the
+// project.
+func (r *trivy) IsIgnored(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, ignore := range r.Ignore {
+		if strings.TrimSpace(ignore) == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"testing"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindings(t *testing.T) {
+	prov := &Provenance{Ref: "main"}
+	trivyResults := TrivyResults{
+		&trivy{
+			ProjName:      "proj1",
+			ProjNamespace: "group/sub",
+			Group:         "group/sub",
+			Instance:      "internal",
+			Label:         "platform",
+			Ignore:        []string{"CVE-2018-16487", " DS002 "},
+			ReportResult: Results{
+				{
+					Provenance: prov,
+					Result: types.Result{
+						Target: "node-app/package-lock.json",
+						Vulnerabilities: []types.DetectedVulnerability{
+							{
+								VulnerabilityID:  "CVE-2018-16487",
+								PkgName:          "lodash",
+								InstalledVersion: "4.17.4",
+								FixedVersion:     ">=4.17.11",
+								Vulnerability: dbtypes.Vulnerability{
+									Title:    "lodash: Prototype pollution",
+									Severity: "HIGH",
+									CVSS:     dbtypes.VendorCVSS{"nvd": {V3Score: 5.6}},
+								},
+							},
+						},
+					},
+				},
+				{
+					Provenance: prov,
+					Result: types.Result{
+						Target: "Dockerfile",
+						Misconfigurations: []types.DetectedMisconfiguration{
+							{ID: "DS001", Severity: "MEDIUM", Title: "latest tag", Status: types.MisconfStatusPassed},
+							{ID: "DS002", Severity: "HIGH", Title: "root user", Status: types.MisconfStatusFailure},
+							{ID: "DS005", Severity: "LOW", Title: "ADD instead of COPY", Status: types.MisconfStatusException},
+						},
+						Secrets:  []types.DetectedSecret{{RuleID: "github-pat", Severity: "CRITICAL", Title: "GitHub PAT"}},
+						Licenses: []types.DetectedLicense{{Name: "GPL-3.0", PkgName: "bash", Severity: "HIGH", Category: ftypes.CategoryRestricted}},
+					},
+				},
+			},
+		},
+	}
+
+	findings := trivyResults.Findings()
+	assert.Len(t, findings, 4)
+	assert.Equal(t, Finding{
+		Group:     "group/sub",
+		Instance:  "internal",
+		Label:     "platform",
+		Project:   "proj1",
+		Namespace: "group/sub",
+		Ref:       "main",
+		Target:    "node-app/package-lock.json",
+		Class:     ClassVulnerability,
+		ID:        "CVE-2018-16487",
+		Package:   "lodash",
+		Installed: "4.17.4",
+		Fixed:     ">=4.17.11",
+		Severity:  "HIGH",
+		CVSS:      5.6,
+		Title:     "lodash: Prototype pollution",
+		Ignored:   true,
+	}, findings[0])
+	assert.Equal(t, ClassMisconfiguration, findings[1].Class)
+	assert.Equal(t, "DS002", findings[1].ID)
+	assert.True(t, findings[1].Ignored)
+	assert.Equal(t, ClassSecret, findings[2].Class)
+	assert.False(t, findings[2].Ignored)
+	assert.Equal(t, ClassLicense, findings[3].Class)
+	assert.Equal(t, "GPL-3.0", findings[3].ID)
+	assert.Equal(t, "bash", findings[3].Package)
+}
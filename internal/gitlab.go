@@ -2,7 +2,10 @@ package internal
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	logger "github.com/sirupsen/logrus"
 	"github.com/xanzy/go-gitlab"
@@ -14,13 +17,145 @@ type GitLabClient struct {
 	JobsClient      GitLabJobs
 	PipelinesClient GitLabPipelines
 	RepositoryFiles GitLabRepositoryFiles
+	ListOptions     ProjectListOptions
+}
+
+// ProjectListOptions are passed to GitLab when the projects are listed, so
+// that only matching projects are downloaded. Empty values don't filter.
+type ProjectListOptions struct {
+	Topic                   string
+	Search                  string
+	WithProgrammingLanguage string
+	LastActivityAfter       *time.Time
+	Visibility              string
+	IncludeArchived         bool
+	ExcludeForks            bool
+	ExcludeShared           bool
+}
+
+// NewProjectListOptions validates the options. lastActivityAfter is either a
+// date (2006-01-02), a RFC3339 timestamp or an age like 90d or 72h.
+func NewProjectListOptions(topic, search, language, lastActivityAfter, visibility string) (ProjectListOptions, error) {
+	opts := ProjectListOptions{
+		Topic:                   strings.TrimSpace(topic),
+		Search:                  strings.TrimSpace(search),
+		WithProgrammingLanguage: strings.TrimSpace(language),
+		Visibility:              strings.ToLower(strings.TrimSpace(visibility)),
+	}
+	if opts.Visibility != "" && !contains(visibilities, opts.Visibility) {
+		return opts, fmt.Errorf("unknown visibility %q, use one of [%s]", opts.Visibility, strings.Join(visibilities, ", "))
+	}
+	if lastActivityAfter = strings.TrimSpace(lastActivityAfter); lastActivityAfter != "" {
+		after, err := parseTime(lastActivityAfter)
+		if err != nil {
+			return opts, err
+		}
+		opts.LastActivityAfter = &after
+	}
+	return opts, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither a date, a timestamp nor a duration", value)
+	}
+	return now().Add(-age), nil
+}
+
+// groupOptions returns the options to list the projects of a group. GitLab
+// doesn't filter group projects by programming language or last activity, the
+// last activity is checked by filterProjects instead.
+func (o ProjectListOptions) groupOptions() (*gitlab.ListGroupProjectsOptions, error) {
+	if o.WithProgrammingLanguage != "" {
+		return nil, fmt.Errorf("GitLab doesn't support filtering group projects by programming language, use it without group")
+	}
+	options := &gitlab.ListGroupProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+		Archived:         gitlab.Ptr(false),
+		IncludeSubGroups: gitlab.Ptr(true),
+	}
+	if o.IncludeArchived {
+		options.Archived = nil
+	}
+	if o.ExcludeShared {
+		options.WithShared = gitlab.Ptr(false)
+	}
+	if o.Topic != "" {
+		options.Topic = gitlab.Ptr(o.Topic)
+	}
+	if o.Search != "" {
+		options.Search = gitlab.Ptr(o.Search)
+	}
+	if o.Visibility != "" {
+		options.Visibility = gitlab.Ptr(gitlab.VisibilityValue(o.Visibility))
+	}
+	return options, nil
+}
+
+func (o ProjectListOptions) userOptions() *gitlab.ListProjectsOptions {
+	options := &gitlab.ListProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+		Archived:          gitlab.Ptr(false),
+		MinAccessLevel:    gitlab.AccessLevel(gitlab.DeveloperPermissions),
+		LastActivityAfter: o.LastActivityAfter,
+	}
+	if o.IncludeArchived {
+		options.Archived = nil
+	}
+	if o.Topic != "" {
+		options.Topic = gitlab.Ptr(o.Topic)
+	}
+	if o.Search != "" {
+		options.Search = gitlab.Ptr(o.Search)
+	}
+	if o.WithProgrammingLanguage != "" {
+		options.WithProgrammingLanguage = gitlab.Ptr(o.WithProgrammingLanguage)
+	}
+	if o.Visibility != "" {
+		options.Visibility = gitlab.Ptr(gitlab.VisibilityValue(o.Visibility))
+	}
+	return options
+}
+
+// filterProjects removes the projects which GitLab can't filter itself. Forks
+// can't be filtered by GitLab at all, the last activity only for group
+// projects.
+func (o ProjectListOptions) filterProjects(projs []*gitlab.Project, group bool) []*gitlab.Project {
+	activityAfter := o.LastActivityAfter
+	if !group {
+		activityAfter = nil
+	}
+	if !o.ExcludeForks && activityAfter == nil {
+		return projs
+	}
+	return filterSlice(projs, func(proj *gitlab.Project) bool {
+		if o.ExcludeForks && proj.ForkedFromProject != nil {
+			return false
+		}
+		return activityAfter == nil || (proj.LastActivityAt != nil && proj.LastActivityAt.After(*activityAfter))
+	})
 }
 
 type GitLabGroups interface {
+	GetGroup(gid interface{}, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error)
 	ListGroupProjects(gid interface{}, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error)
 }
 
 type GitLabProjects interface {
+	GetProject(pid interface{}, opt *gitlab.GetProjectOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error)
 	ListProjects(opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error)
 }
 
@@ -53,13 +188,9 @@ func (c GitLabClient) GetProjects(groupId string) ([]*gitlab.Project, error) {
 
 func (c GitLabClient) GetAllGroupProjects(groupId string) ([]*gitlab.Project, error) {
 	allProjs := []*gitlab.Project{}
-	var options = &gitlab.ListGroupProjectsOptions{
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-			Page:    1,
-		},
-		Archived:         gitlab.Ptr(false),
-		IncludeSubGroups: gitlab.Ptr(true),
+	options, err := c.ListOptions.groupOptions()
+	if err != nil {
+		return nil, err
 	}
 	var wg sync.WaitGroup
 
@@ -86,7 +217,7 @@ func (c GitLabClient) GetAllGroupProjects(groupId string) ([]*gitlab.Project, er
 		allProjs = append(allProjs, result.projs...)
 	}
 
-	return allProjs, nil
+	return c.ListOptions.filterProjects(allProjs, true), nil
 }
 
 func (c GitLabClient) listGroupProjectsWrapper(grpId string, options gitlab.ListGroupProjectsOptions, resultChannel chan wrapper, wg *sync.WaitGroup) {
@@ -97,14 +228,7 @@ func (c GitLabClient) listGroupProjectsWrapper(grpId string, options gitlab.List
 
 func (c GitLabClient) GetAllUserProjects() ([]*gitlab.Project, error) {
 	allProjs := []*gitlab.Project{}
-	options := &gitlab.ListProjectsOptions{
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-			Page:    1,
-		},
-		Archived:       gitlab.Ptr(false),
-		MinAccessLevel: gitlab.AccessLevel(gitlab.DeveloperPermissions),
-	}
+	options := c.ListOptions.userOptions()
 	var wg sync.WaitGroup
 
 	projs, resp, err := c.ProjectsClient.ListProjects(options)
@@ -130,7 +254,7 @@ func (c GitLabClient) GetAllUserProjects() ([]*gitlab.Project, error) {
 		allProjs = append(allProjs, result.projs...)
 	}
 
-	return allProjs, nil
+	return c.ListOptions.filterProjects(allProjs, false), nil
 }
 
 func (c GitLabClient) listProjectsWrapper(options gitlab.ListProjectsOptions, resultCHannel chan wrapper, wg *sync.WaitGroup) {
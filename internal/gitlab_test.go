@@ -2,16 +2,18 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/steffakasid/trivy-scanner/internal/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xanzy/go-gitlab"
 )
 
 func TestGetAllGroupProjects(t *testing.T) {
 	gitLabClient := InitMock()
 
-	expectedProjs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 2, "unittest")
+	expectedProjs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 2, "unittest", defaultGroupOptions())
 
 	projs, err := gitLabClient.GetAllGroupProjects("unittest")
 	assert.NoError(t, err)
@@ -19,27 +21,158 @@ func TestGetAllGroupProjects(t *testing.T) {
 	gitLabClient.GroupsClient.(*mocks.GitLabGroups).AssertExpectations(t)
 }
 
+func TestGetAllGroupProjectsWithListOptions(t *testing.T) {
+	t.Run("server side filters", func(t *testing.T) {
+		gitLabClient := InitMock()
+		var err error
+		gitLabClient.ListOptions, err = NewProjectListOptions("docker", "api", "", "", "Internal")
+		require.NoError(t, err)
+
+		options := defaultGroupOptions()
+		options.Topic = gitlab.Ptr("docker")
+		options.Search = gitlab.Ptr("api")
+		options.Visibility = gitlab.Ptr(gitlab.InternalVisibility)
+		expectedProjs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 2, "unittest", options)
+
+		projs, err := gitLabClient.GetAllGroupProjects("unittest")
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, projs, expectedProjs)
+		gitLabClient.GroupsClient.(*mocks.GitLabGroups).AssertExpectations(t)
+	})
+
+	t.Run("last activity is filtered client side", func(t *testing.T) {
+		gitLabClient := InitMock()
+		var err error
+		gitLabClient.ListOptions, err = NewProjectListOptions("", "", "", "2026-01-01", "")
+		require.NoError(t, err)
+
+		projs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 1, "unittest", defaultGroupOptions())
+		active := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		inactive := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		projs[0].LastActivityAt = &active
+		projs[1].LastActivityAt = &inactive
+
+		filtered, err := gitLabClient.GetAllGroupProjects("unittest")
+		assert.NoError(t, err)
+		assert.Equal(t, projs[:1], filtered)
+	})
+
+	t.Run("archived, shared and forks", func(t *testing.T) {
+		gitLabClient := InitMock()
+		gitLabClient.ListOptions = ProjectListOptions{IncludeArchived: true, ExcludeShared: true, ExcludeForks: true}
+
+		options := defaultGroupOptions()
+		options.Archived = nil
+		options.WithShared = gitlab.Ptr(false)
+		projs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 1, "unittest", options)
+		projs[0].ForkedFromProject = &gitlab.ForkParent{ID: 1}
+
+		filtered, err := gitLabClient.GetAllGroupProjects("unittest")
+		assert.NoError(t, err)
+		assert.Equal(t, projs[1:], filtered)
+	})
+
+	t.Run("programming language is not supported", func(t *testing.T) {
+		gitLabClient := InitMock()
+		var err error
+		gitLabClient.ListOptions, err = NewProjectListOptions("", "", "Go", "", "")
+		require.NoError(t, err)
+
+		projs, err := gitLabClient.GetAllGroupProjects("unittest")
+		assert.Nil(t, projs)
+		assert.EqualError(t, err, "GitLab doesn't support filtering group projects by programming language, use it without group")
+	})
+}
+
 func TestGetAllUserProjects(t *testing.T) {
 	gitLabClient := InitMock()
 
-	expectedProjs := mockListProjects(gitLabClient.ProjectsClient.(*mocks.GitLabProjects), 2)
+	expectedProjs := mockListProjects(gitLabClient.ProjectsClient.(*mocks.GitLabProjects), 2, defaultUserOptions())
+
+	projs, err := gitLabClient.GetAllUserProjects()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, projs, expectedProjs)
+}
+
+func TestGetAllUserProjectsWithListOptions(t *testing.T) {
+	gitLabClient := InitMock()
+	var err error
+	gitLabClient.ListOptions, err = NewProjectListOptions("docker", "api", "Go", "2026-01-01T10:00:00Z", "private")
+	require.NoError(t, err)
+
+	options := defaultUserOptions()
+	options.Topic = gitlab.Ptr("docker")
+	options.Search = gitlab.Ptr("api")
+	options.WithProgrammingLanguage = gitlab.Ptr("Go")
+	options.LastActivityAfter = gitlab.Ptr(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	options.Visibility = gitlab.Ptr(gitlab.PrivateVisibility)
+	expectedProjs := mockListProjects(gitLabClient.ProjectsClient.(*mocks.GitLabProjects), 2, options)
 
 	projs, err := gitLabClient.GetAllUserProjects()
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, projs, expectedProjs)
+	gitLabClient.ProjectsClient.(*mocks.GitLabProjects).AssertExpectations(t)
+}
+
+func TestGetAllUserProjectsWithArchivedAndForks(t *testing.T) {
+	gitLabClient := InitMock()
+	gitLabClient.ListOptions = ProjectListOptions{IncludeArchived: true, ExcludeForks: true}
+
+	options := defaultUserOptions()
+	options.Archived = nil
+	projs := mockListProjects(gitLabClient.ProjectsClient.(*mocks.GitLabProjects), 1, options)
+	projs[1].ForkedFromProject = &gitlab.ForkParent{ID: 1}
+
+	filtered, err := gitLabClient.GetAllUserProjects()
+	assert.NoError(t, err)
+	assert.Equal(t, projs[:1], filtered)
+}
+
+func TestNewProjectListOptions(t *testing.T) {
+	current := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+
+	opts, err := NewProjectListOptions("", "", "", "30d", "")
+	require.NoError(t, err)
+	assert.Equal(t, current.AddDate(0, 0, -30), *opts.LastActivityAfter)
+
+	opts, err = NewProjectListOptions("", "", "", "2026-05-01", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), *opts.LastActivityAfter)
+
+	_, err = NewProjectListOptions("", "", "", "yesterday", "")
+	assert.EqualError(t, err, "yesterday is neither a date, a timestamp nor a duration")
+
+	_, err = NewProjectListOptions("", "", "", "", "hidden")
+	assert.EqualError(t, err, `unknown visibility "hidden", use one of [public, internal, private]`)
 }
 
-func mockListGroupProjects(mock *mocks.GitLabGroups, numCalls int, grpId string) []*gitlab.Project {
+func defaultGroupOptions() gitlab.ListGroupProjectsOptions {
+	return gitlab.ListGroupProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+		},
+		Archived:         gitlab.Ptr(false),
+		IncludeSubGroups: gitlab.Ptr(true),
+	}
+}
+
+func defaultUserOptions() gitlab.ListProjectsOptions {
+	return gitlab.ListProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 100,
+		},
+		Archived:       gitlab.Ptr(false),
+		MinAccessLevel: gitlab.AccessLevel(gitlab.DeveloperPermissions),
+	}
+}
+
+func mockListGroupProjects(mock *mocks.GitLabGroups, numCalls int, grpId string, options gitlab.ListGroupProjectsOptions) []*gitlab.Project {
 	expectedProjs := []*gitlab.Project{}
 	for i := 1; i <= numCalls; i++ {
-		options := &gitlab.ListGroupProjectsOptions{
-			ListOptions: gitlab.ListOptions{
-				PerPage: 100,
-				Page:    i,
-			},
-			Archived:         gitlab.Ptr(false),
-			IncludeSubGroups: gitlab.Ptr(true),
-		}
+		pageOptions := options
+		pageOptions.Page = i
 		projects := []*gitlab.Project{
 			{
 				ID: 10 * i,
@@ -52,22 +185,16 @@ func mockListGroupProjects(mock *mocks.GitLabGroups, numCalls int, grpId string)
 		response := &gitlab.Response{
 			TotalPages: numCalls,
 		}
-		mock.EXPECT().ListGroupProjects(grpId, options).Return(projects, response, nil).Once()
+		mock.EXPECT().ListGroupProjects(grpId, &pageOptions).Return(projects, response, nil).Once()
 	}
 	return expectedProjs
 }
 
-func mockListProjects(mock *mocks.GitLabProjects, numCalls int) []*gitlab.Project {
+func mockListProjects(mock *mocks.GitLabProjects, numCalls int, options gitlab.ListProjectsOptions) []*gitlab.Project {
 	expectedProjs := []*gitlab.Project{}
 	for i := 1; i <= numCalls; i++ {
-		options := &gitlab.ListProjectsOptions{
-			ListOptions: gitlab.ListOptions{
-				PerPage: 100,
-				Page:    i,
-			},
-			Archived:       gitlab.Ptr(false),
-			MinAccessLevel: gitlab.AccessLevel(gitlab.DeveloperPermissions),
-		}
+		pageOptions := options
+		pageOptions.Page = i
 		projects := []*gitlab.Project{
 			{
 				ID: 10 * i,
@@ -80,7 +207,7 @@ func mockListProjects(mock *mocks.GitLabProjects, numCalls int) []*gitlab.Projec
 		response := &gitlab.Response{
 			TotalPages: numCalls,
 		}
-		mock.EXPECT().ListProjects(options).Return(projects, response, nil).Once()
+		mock.EXPECT().ListProjects(&pageOptions).Return(projects, response, nil).Once()
 	}
 	return expectedProjs
 }
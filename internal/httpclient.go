@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPConfig configures the http client used to access GitLab.
+type HTTPConfig struct {
+	CAFile             string        `mapstructure:"caFile"`
+	ClientCert         string        `mapstructure:"clientCert"`
+	ClientKey          string        `mapstructure:"clientKey"`
+	InsecureSkipVerify bool          `mapstructure:"insecureSkipVerify"`
+	Proxy              string        `mapstructure:"proxy"`
+	Timeout            time.Duration `mapstructure:"timeout"`
+}
+
+// Merge returns the config with all empty values taken from defaults.
+// InsecureSkipVerify is enabled if it's enabled in one of both.
+func (c HTTPConfig) Merge(defaults HTTPConfig) HTTPConfig {
+	if c.CAFile == "" {
+		c.CAFile = defaults.CAFile
+	}
+	if c.ClientCert == "" && c.ClientKey == "" {
+		c.ClientCert = defaults.ClientCert
+		c.ClientKey = defaults.ClientKey
+	}
+	if c.Proxy == "" {
+		c.Proxy = defaults.Proxy
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaults.Timeout
+	}
+	c.InsecureSkipVerify = c.InsecureSkipVerify || defaults.InsecureSkipVerify
+	return c
+}
+
+// NewHTTPClient creates the http client for the config. The CA bundle is
+// trusted in addition to the system certificates. Without proxy the proxy
+// environment variables (HTTPS_PROXY, NO_PROXY) are used. The timeout only
+// limits the wait for the response headers, so that large artifacts can be
+// downloaded.
+func NewHTTPClient(config HTTPConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		if config.ClientCert == "" || config.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and key have to be given both")
+		}
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.ResponseHeaderTimeout = config.Timeout
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%s is not a valid proxy url", config.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	t.Run("unknown CA", func(t *testing.T) {
+		client, err := NewHTTPClient(HTTPConfig{})
+		require.NoError(t, err)
+		_, err = client.Get(server.URL)
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("CA bundle", func(t *testing.T) {
+		client, err := NewHTTPClient(HTTPConfig{CAFile: caFile})
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		client, err := NewHTTPClient(HTTPConfig{InsecureSkipVerify: true})
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		certFile, keyFile, cert := newClientCert(t, dir)
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		mtls := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		mtls.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		mtls.StartTLS()
+		t.Cleanup(mtls.Close)
+
+		client, err := NewHTTPClient(HTTPConfig{InsecureSkipVerify: true})
+		require.NoError(t, err)
+		_, err = client.Get(mtls.URL)
+		assert.Error(t, err)
+
+		client, err = NewHTTPClient(HTTPConfig{InsecureSkipVerify: true, ClientCert: certFile, ClientKey: keyFile})
+		require.NoError(t, err)
+		resp, err := client.Get(mtls.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("proxy and timeout", func(t *testing.T) {
+		client, err := NewHTTPClient(HTTPConfig{Proxy: "http://proxy.example.com:3128", Timeout: 5 * time.Second})
+		require.NoError(t, err)
+		assert.Zero(t, client.Timeout)
+		assert.Equal(t, 5*time.Second, client.Transport.(*http.Transport).ResponseHeaderTimeout)
+		proxy, err := client.Transport.(*http.Transport).Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "gitlab.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, "http://proxy.example.com:3128", proxy.String())
+	})
+
+	t.Run("timeout only limits the response headers", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/headers" {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			for i := 0; i < 4; i++ {
+				time.Sleep(50 * time.Millisecond)
+				w.Write([]byte("chunk"))
+				w.(http.Flusher).Flush()
+			}
+		}))
+		t.Cleanup(slow.Close)
+		client, err := NewHTTPClient(HTTPConfig{Timeout: 100 * time.Millisecond})
+		require.NoError(t, err)
+
+		resp, err := client.Get(slow.URL + "/body")
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "chunkchunkchunkchunk", string(body))
+
+		_, err = client.Get(slow.URL + "/headers")
+		assert.ErrorContains(t, err, "timeout awaiting response headers")
+	})
+
+	errTests := []struct {
+		name   string
+		config HTTPConfig
+		err    string
+	}{
+		{name: "missing CA bundle", config: HTTPConfig{CAFile: filepath.Join(dir, "missing.pem")}, err: "failed to read CA bundle"},
+		{name: "empty CA bundle", config: HTTPConfig{CAFile: writePEM(t, dir, "empty.pem", "", nil)}, err: "no certificates found in CA bundle"},
+		{name: "key without certificate", config: HTTPConfig{ClientKey: "key.pem"}, err: "client certificate and key have to be given both"},
+		{name: "invalid client certificate", config: HTTPConfig{ClientCert: caFile, ClientKey: caFile}, err: "failed to load client certificate"},
+		{name: "invalid proxy", config: HTTPConfig{Proxy: "proxy:3128"}, err: "proxy:3128 is not a valid proxy url"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.config)
+			assert.Nil(t, client)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestHTTPConfigMerge(t *testing.T) {
+	defaults := HTTPConfig{CAFile: "ca.pem", ClientCert: "cert.pem", ClientKey: "key.pem", Proxy: "http://proxy:3128", Timeout: time.Minute}
+	assert.Equal(t, defaults, HTTPConfig{}.Merge(defaults))
+	assert.Equal(t,
+		HTTPConfig{CAFile: "internal.pem", ClientCert: "other.pem", ClientKey: "other.key", InsecureSkipVerify: true, Proxy: "http://proxy:3128", Timeout: time.Second},
+		HTTPConfig{CAFile: "internal.pem", ClientCert: "other.pem", ClientKey: "other.key", InsecureSkipVerify: true, Timeout: time.Second}.Merge(defaults))
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	file := filepath.Join(dir, name)
+	content := []byte{}
+	if der != nil {
+		content = pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+	require.NoError(t, os.WriteFile(file, content, 0o600))
+	return file
+}
+
+func newClientCert(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "trivyops"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER), cert
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const INSTANCES = "instances"
+
+// Instance is a GitLab instance configured in the instances list of the config
+// file. All instances are scanned in one run and their name is added to the
+// results.
+type Instance struct {
+	Name        string      `mapstructure:"name"`
+	Host        string      `mapstructure:"host"`
+	Groups      []string    `mapstructure:"groups"`
+	Projects    []string    `mapstructure:"projects"`
+	Credentials Credentials `mapstructure:",squash"`
+	HTTP        HTTPConfig  `mapstructure:",squash"`
+}
+
+// LoadInstances reads and validates the configured instances. It returns no
+// instance if none is configured. The host defaults to GITLAB_HOST.
+func LoadInstances() ([]Instance, error) {
+	instances := []Instance{}
+	if err := viper.UnmarshalKey(INSTANCES, &instances); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", INSTANCES, err)
+	}
+	names := map[string]bool{}
+	for i := range instances {
+		instance := &instances[i]
+		instance.Name = strings.TrimSpace(instance.Name)
+		if instance.Name == "" {
+			return nil, fmt.Errorf("instance %d has no name", i+1)
+		}
+		if names[instance.Name] {
+			return nil, fmt.Errorf("instance %s is configured more than once", instance.Name)
+		}
+		names[instance.Name] = true
+		if instance.Host == "" {
+			instance.Host = viper.GetString(GITLAB_HOST)
+		}
+		if instance.Credentials.Token == "" && instance.Credentials.TokenFile == "" {
+			return nil, fmt.Errorf("instance %s has no token", instance.Name)
+		}
+		credentials, err := instance.Credentials.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.Name, err)
+		}
+		instance.Credentials = credentials
+	}
+	return instances, nil
+}
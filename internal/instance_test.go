@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadInstances(t *testing.T) {
+	load := func(t *testing.T, config string) ([]Instance, error) {
+		viper.SetConfigType(configFileType)
+		require.NoError(t, viper.ReadConfig(bytes.NewBufferString(config)))
+		t.Cleanup(func() {
+			require.NoError(t, viper.ReadConfig(bytes.NewBufferString("")))
+		})
+		return LoadInstances()
+	}
+
+	t.Run("no instances", func(t *testing.T) {
+		instances, err := load(t, "GITLAB_HOST: https://gitlab.example.com\n")
+		require.NoError(t, err)
+		assert.Empty(t, instances)
+	})
+
+	t.Run("instances", func(t *testing.T) {
+		instances, err := load(t, `
+instances:
+  - name: gitlab.com
+    token: glpat-public
+    groups: [1234]
+  - name: internal
+    host: https://gitlab.example.com
+    token: glpat-internal
+    tokenType: oauth
+    groups:
+      - platform=platform
+    projects:
+      - security/tools
+    caFile: /etc/ssl/internal.pem
+    timeout: 30s
+`)
+		require.NoError(t, err)
+		assert.Equal(t, []Instance{
+			{Name: "gitlab.com", Host: "https://gitlab.com", Groups: []string{"1234"}, Credentials: Credentials{TokenType: TokenTypePersonal, Token: "glpat-public"}},
+			{Name: "internal", Host: "https://gitlab.example.com", Groups: []string{"platform=platform"}, Projects: []string{"security/tools"}, Credentials: Credentials{TokenType: TokenTypeOAuth, Token: "glpat-internal"}, HTTP: HTTPConfig{CAFile: "/etc/ssl/internal.pem", Timeout: 30 * time.Second}},
+		}, instances)
+	})
+
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{name: "missing name", config: "instances:\n  - token: abc\n", err: "instance 1 has no name"},
+		{name: "duplicate name", config: "instances:\n  - name: a\n    token: abc\n  - name: a\n    token: def\n", err: "instance a is configured more than once"},
+		{name: "missing token", config: "instances:\n  - name: a\n    host: https://gitlab.example.com\n", err: "instance a has no token"},
+		{name: "job token isn't used for gitlab.com", config: "instances:\n  - name: gitlab.com\n  - name: internal\n    host: https://gitlab.example.com\n    token: glpat\n", err: "instance gitlab.com has no token"},
+		{name: "job token isn't used for other hosts", config: "instances:\n  - name: gitlab.com\n    token: glpat\n  - name: internal\n    host: https://gitlab.example.com\n", err: "instance internal has no token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CI_JOB_TOKEN, "job")
+			instances, err := load(t, tt.config)
+			assert.Nil(t, instances)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package internal
+
+import (
+	"time"
+)
+
+// JSONSchemaVersion is the version of the json output described by
+// schema/report.schema.json. The minor version is increased for new optional
+// fields, the major version for changes which break existing consumers.
+const JSONSchemaVersion = "1.4.0"
+
+// JSONReport is the documented json output of trivyops. In contrast to the
+// internal structs its field names are stable and don't change with trivy.
+type JSONReport struct {
+	SchemaVersion string        `json:"schemaVersion"`
+	Metadata      JSONMetadata  `json:"metadata"`
+	Projects      []JSONProject `json:"projects"`
+}
+
+// JSONMetadata describes the scan which created the report.
+type JSONMetadata struct {
+	Tool         string    `json:"tool"`
+	ToolVersion  string    `json:"toolVersion"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+	GitLabHost   string    `json:"gitlabHost,omitempty"`
+	Instances    []string  `json:"instances,omitempty"`
+	GroupID      string    `json:"groupId,omitempty"`
+	JobName      string    `json:"jobName,omitempty"`
+	ArtifactName string    `json:"artifactName,omitempty"`
+}
+
+// JSONProject holds the summary, the report sources and all findings of one
+// project.
+type JSONProject struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Instance    string        `json:"instance,omitempty"`
+	Label       string        `json:"label,omitempty"`
+	Archived    bool          `json:"archived,omitempty"`
+	Fork        bool          `json:"fork,omitempty"`
+	Shared      bool          `json:"shared,omitempty"`
+	Group       string        `json:"group,omitempty"`
+	TrivyIgnore []string      `json:"trivyIgnore"`
+	Summary     JSONSummary   `json:"summary"`
+	Sources     []JSONSource  `json:"sources"`
+	Findings    []JSONFinding `json:"findings"`
+}
+
+// JSONSummary counts the findings of one project.
+type JSONSummary struct {
+	Vulnerabilities         int `json:"vulnerabilities"`
+	Critical                int `json:"critical"`
+	High                    int `json:"high"`
+	Medium                  int `json:"medium"`
+	Low                     int `json:"low"`
+	Unknown                 int `json:"unknown"`
+	Secrets                 int `json:"secrets"`
+	MisconfigurationsFailed int `json:"misconfigurationsFailed"`
+	Licenses                int `json:"licenses"`
+	DisallowedLicenses      int `json:"disallowedLicenses"`
+}
+
+// JSONSource is the CI job and artifact a trivy report was taken from.
+type JSONSource struct {
+	JobName      string    `json:"jobName"`
+	JobID        int       `json:"jobId"`
+	PipelineID   int       `json:"pipelineId"`
+	Ref          string    `json:"ref"`
+	CommitSHA    string    `json:"commitSha"`
+	ArtifactFile string    `json:"artifactFile"`
+	ArtifactName string    `json:"artifactName,omitempty"`
+	CreatedAt    time.Time `json:"createdAt,omitzero"`
+	OSFamily     string    `json:"osFamily,omitempty"`
+	OSVersion    string    `json:"osVersion,omitempty"`
+	EOSL         bool      `json:"eosl,omitempty"`
+	ImageDigest  string    `json:"imageDigest,omitempty"`
+}
+
+// JSONFinding is a single vulnerability, misconfiguration, secret or license.
+// JobID refers to the source the finding was reported by.
+type JSONFinding struct {
+	Class            string  `json:"class"`
+	ID               string  `json:"id"`
+	Target           string  `json:"target"`
+	JobID            int     `json:"jobId,omitempty"`
+	Severity         string  `json:"severity"`
+	Title            string  `json:"title,omitempty"`
+	Package          string  `json:"package,omitempty"`
+	InstalledVersion string  `json:"installedVersion,omitempty"`
+	FixedVersion     string  `json:"fixedVersion,omitempty"`
+	CVSS             float64 `json:"cvss,omitempty"`
+	URL              string  `json:"url,omitempty"`
+	Status           string  `json:"status,omitempty"`
+	Location         string  `json:"location,omitempty"`
+	Category         string  `json:"category,omitempty"`
+	Match            string  `json:"match,omitempty"`
+	Disallowed       bool    `json:"disallowed,omitempty"`
+	Ignored          bool    `json:"ignored"`
+}
+
+// ToJSONReport converts the results into the versioned json report.
+func (t TrivyResults) ToJSONReport(metadata JSONMetadata) JSONReport {
+	report := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Metadata:      metadata,
+		Projects:      []JSONProject{},
+	}
+	for _, result := range t {
+		report.Projects = append(report.Projects, result.toJSONProject())
+	}
+	return report
+}
+
+func (r *trivy) toJSONProject() JSONProject {
+	proj := JSONProject{
+		ID:          r.ProjId,
+		Name:        r.ProjName,
+		Namespace:   r.ProjNamespace,
+		Instance:    r.Instance,
+		Label:       r.Label,
+		Archived:    r.Archived,
+		Fork:        r.Fork,
+		Shared:      r.Shared,
+		Group:       r.Group,
+		TrivyIgnore: r.Ignore,
+		Summary: JSONSummary{
+			Secrets:                 r.Vulnerabilities.Secrets,
+			MisconfigurationsFailed: r.Vulnerabilities.Misconfigurations.Fail,
+			Licenses:                r.Vulnerabilities.Licenses.Count,
+			DisallowedLicenses:      r.Vulnerabilities.Licenses.Disallowed,
+		},
+		Sources:  []JSONSource{},
+		Findings: []JSONFinding{},
+	}
+	if proj.TrivyIgnore == nil {
+		proj.TrivyIgnore = []string{}
+	}
+
+	for _, report := range r.Reports {
+		if report.Provenance == nil {
+			continue
+		}
+		source := JSONSource{
+			JobName:      report.Provenance.JobName,
+			JobID:        report.Provenance.JobID,
+			PipelineID:   report.Provenance.PipelineID,
+			Ref:          report.Provenance.Ref,
+			CommitSHA:    report.Provenance.CommitSHA,
+			ArtifactFile: report.Provenance.ArtifactFile,
+			ArtifactName: report.Provenance.ArtifactName,
+			CreatedAt:    report.Provenance.CreatedAt,
+			ImageDigest:  imageDigest(report),
+		}
+		if report.Metadata.OS != nil {
+			source.OSFamily = string(report.Metadata.OS.Family)
+			source.OSVersion = report.Metadata.OS.Name
+			source.EOSL = report.Metadata.OS.Eosl
+		}
+		proj.Sources = append(proj.Sources, source)
+	}
+
+	for _, tgt := range r.ReportResult {
+		crit, hi, med, lo, un := GetSummary(tgt.Vulnerabilities)
+		proj.Summary.Vulnerabilities += len(tgt.Vulnerabilities)
+		proj.Summary.Critical += crit
+		proj.Summary.High += hi
+		proj.Summary.Medium += med
+		proj.Summary.Low += lo
+		proj.Summary.Unknown += un
+
+		for _, v := range tgt.Vulnerabilities {
+			proj.Findings = append(proj.Findings, JSONFinding{
+				Class:            ClassVulnerability,
+				ID:               v.VulnerabilityID,
+				Target:           tgt.Target,
+				JobID:            jobID(tgt.Provenance),
+				Severity:         v.Severity,
+				Title:            v.Title,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				CVSS:             CVSSScore(v),
+				URL:              v.PrimaryURL,
+				Ignored:          r.IsIgnored(v.VulnerabilityID),
+			})
+		}
+		for _, m := range tgt.Misconfigurations {
+			proj.Findings = append(proj.Findings, JSONFinding{
+				Class:    ClassMisconfiguration,
+				ID:       m.ID,
+				Target:   tgt.Target,
+				JobID:    jobID(tgt.Provenance),
+				Severity: m.Severity,
+				Title:    m.Title,
+				URL:      m.PrimaryURL,
+				Status:   string(m.Status),
+				Location: MisconfLocation(tgt.Target, m),
+				Ignored:  r.IsIgnored(m.ID) || r.IsIgnored(m.AVDID),
+			})
+		}
+	}
+	for _, s := range r.Secrets {
+		proj.Findings = append(proj.Findings, JSONFinding{
+			Class:    ClassSecret,
+			ID:       s.RuleID,
+			Target:   s.Target,
+			JobID:    jobID(s.Provenance),
+			Severity: s.Severity,
+			Title:    s.Title,
+			Location: s.Location(),
+			Category: string(s.Category),
+			Match:    s.Match,
+			Ignored:  r.IsIgnored(s.RuleID),
+		})
+	}
+	for _, l := range r.Licenses {
+		proj.Findings = append(proj.Findings, JSONFinding{
+			Class:      ClassLicense,
+			ID:         l.Name,
+			Target:     l.Target,
+			JobID:      jobID(l.Provenance),
+			Severity:   l.Severity,
+			Package:    l.PkgName,
+			URL:        l.Link,
+			Location:   l.Location(),
+			Category:   string(l.Category),
+			Disallowed: l.Disallowed,
+			Ignored:    r.IsIgnored(l.Name),
+		})
+	}
+	return proj
+}
+
+func jobID(prov *Provenance) int {
+	if prov == nil {
+		return 0
+	}
+	return prov.JobID
+}
+
+// JSONProjectFinding is a finding together with the project it belongs to.
+// It's used to write single findings e.g. as ndjson.
+type JSONProjectFinding struct {
+	ProjectID int    `json:"projectId"`
+	Project   string `json:"project"`
+	Namespace string `json:"namespace,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Label     string `json:"label,omitempty"`
+	JSONFinding
+}
+
+// ProjectFindings returns the findings of the project including the project
+// they belong to.
+func (p JSONProject) ProjectFindings() []JSONProjectFinding {
+	findings := []JSONProjectFinding{}
+	for _, f := range p.Findings {
+		findings = append(findings, JSONProjectFinding{
+			ProjectID:   p.ID,
+			Project:     p.Name,
+			Namespace:   p.Namespace,
+			Instance:    p.Instance,
+			Label:       p.Label,
+			JSONFinding: f,
+		})
+	}
+	return findings
+}
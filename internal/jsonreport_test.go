@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const jsonReportGolden = "../test/json-report.golden.json"
+
+func TestToJSONReport(t *testing.T) {
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	prov := &Provenance{
+		JobName:      "scan_oci_image_trivy",
+		JobID:        123,
+		PipelineID:   456,
+		Ref:          "main",
+		CommitSHA:    "0123456789abcdef",
+		ArtifactFile: "trivy-results.json",
+		ArtifactName: "registry.example.com/app:1.0",
+		CreatedAt:    created,
+	}
+	trivyResults := TrivyResults{
+		&trivy{
+			ProjId:        1,
+			ProjName:      "app",
+			ProjNamespace: "group/sub",
+			Instance:      "gitlab.com",
+			Label:         "platform",
+			Archived:      true,
+			Shared:        true,
+			Ignore:        []string{"CVE-2024-0002"},
+			Reports: []Report{{
+				Provenance: prov,
+				Metadata: types.Metadata{
+					OS:          &ftypes.OS{Family: "alpine", Name: "3.7.1", Eosl: true},
+					ImageID:     "sha256:1234",
+					RepoDigests: []string{"registry.example.com/app@sha256:abcd"},
+				},
+			}},
+			ReportResult: Results{
+				{Provenance: prov, Result: types.Result{
+					Target: "app (alpine 3.7.1)",
+					Vulnerabilities: []types.DetectedVulnerability{
+						{
+							VulnerabilityID:  "CVE-2024-0001",
+							PkgName:          "openssl",
+							InstalledVersion: "3.0.0",
+							FixedVersion:     "3.0.1",
+							PrimaryURL:       "https://avd.aquasec.com/nvd/cve-2024-0001",
+							Vulnerability: dbtypes.Vulnerability{
+								Title:    "openssl: buffer overflow",
+								Severity: "CRITICAL",
+								CVSS:     dbtypes.VendorCVSS{"nvd": {V3Score: 9.8}},
+							},
+						},
+						{
+							VulnerabilityID:  "CVE-2024-0002",
+							PkgName:          "curl",
+							InstalledVersion: "7.0.0",
+							Vulnerability:    dbtypes.Vulnerability{Severity: "LOW"},
+						},
+					},
+				}},
+				{Provenance: prov, Result: types.Result{
+					Target: "Dockerfile",
+					Misconfigurations: []types.DetectedMisconfiguration{{
+						ID:            "DS002",
+						AVDID:         "AVD-DS-0002",
+						Title:         "Image user should not be 'root'",
+						Severity:      "HIGH",
+						Status:        types.MisconfStatusFailure,
+						CauseMetadata: ftypes.CauseMetadata{StartLine: 1, EndLine: 3},
+					}},
+				}},
+				{Provenance: prov, Result: types.Result{
+					Target:  ".env",
+					Secrets: []types.DetectedSecret{{RuleID: "github-pat", Category: "GitHub", Severity: "CRITICAL", Title: "GitHub Personal Access Token", StartLine: 2, Match: "GITHUB_TOKEN=********"}},
+				}},
+				{Provenance: prov, Result: types.Result{
+					Target:   "node-app/package-lock.json",
+					Licenses: []types.DetectedLicense{{Name: "GPL-3.0", PkgName: "left-pad", Category: ftypes.CategoryRestricted, Severity: "HIGH"}},
+				}},
+			},
+		},
+		&trivy{ProjId: 2, ProjName: "empty", Reports: []Report{{Provenance: &Provenance{JobName: "scan_oci_image_trivy", JobID: 124}}}},
+	}
+	trivyResults.Check()
+	trivyResults.CheckLicenses([]string{"GPL-3.0"})
+
+	report := trivyResults.ToJSONReport(JSONMetadata{
+		Tool:         "trivyops",
+		ToolVersion:  "1.2.3",
+		GeneratedAt:  time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		GitLabHost:   "https://gitlab.com",
+		GroupID:      "1234",
+		JobName:      "scan_oci_image_trivy",
+		ArtifactName: "trivy-results.json",
+	})
+	assert.Equal(t, JSONSchemaVersion, report.SchemaVersion)
+	require.Len(t, report.Projects, 2)
+	assert.Equal(t, JSONSummary{Vulnerabilities: 2, Critical: 1, Low: 1, Secrets: 1, MisconfigurationsFailed: 1, Licenses: 1, DisallowedLicenses: 1}, report.Projects[0].Summary)
+	assert.Len(t, report.Projects[0].Findings, 5)
+	assert.Empty(t, report.Projects[1].Findings)
+
+	findings := report.Projects[0].ProjectFindings()
+	require.Len(t, findings, 5)
+	assert.Equal(t, 1, findings[0].ProjectID)
+	assert.Equal(t, "app", findings[0].Project)
+	assert.Equal(t, "group/sub", findings[0].Namespace)
+	assert.Equal(t, "CVE-2024-0001", findings[0].ID)
+	findingJSON, err := json.Marshal(findings[4])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"projectId": 1, "project": "app", "namespace": "group/sub", "instance": "gitlab.com", "label": "platform", "class": "license", "id": "GPL-3.0", "target": "node-app/package-lock.json", "jobId": 123, "severity": "HIGH", "package": "left-pad", "location": "left-pad", "category": "restricted", "disallowed": true, "ignored": false}`, string(findingJSON))
+
+	bt, err := json.MarshalIndent(report, "", "  ")
+	require.NoError(t, err)
+	bt = append(bt, '\n')
+	assertValidJSON(t, "../schema/report.schema.json", bt)
+
+	if *update {
+		require.NoError(t, os.WriteFile(jsonReportGolden, bt, 0644))
+	}
+	golden, err := os.ReadFile(jsonReportGolden)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(bt))
+
+	// language and config scans have no OS metadata
+	noOS := TrivyResults{&trivy{ProjId: 3, ProjName: "lib", Reports: []Report{{
+		Provenance: prov,
+		Metadata:   types.Metadata{ImageID: "sha256:5678"},
+	}}}}
+	var noOSReport JSONReport
+	require.NotPanics(t, func() { noOSReport = noOS.ToJSONReport(JSONMetadata{}) })
+	require.Len(t, noOSReport.Projects[0].Sources, 1)
+	source := noOSReport.Projects[0].Sources[0]
+	assert.Equal(t, "scan_oci_image_trivy", source.JobName)
+	assert.Empty(t, source.OSFamily)
+	assert.Empty(t, source.OSVersion)
+	assert.False(t, source.EOSL)
+}
+
+func TestJSONReportSchema(t *testing.T) {
+	t.Run("golden file is valid", func(t *testing.T) {
+		golden, err := os.ReadFile(jsonReportGolden)
+		require.NoError(t, err)
+		assertValidJSON(t, "../schema/report.schema.json", golden)
+	})
+
+	t.Run("missing schemaVersion", func(t *testing.T) {
+		schema := compileSchema(t, "../schema/report.schema.json")
+		var doc interface{}
+		require.NoError(t, json.Unmarshal([]byte(`{"metadata": {"tool": "trivyops", "toolVersion": "1.2.3", "generatedAt": "2024-01-03T00:00:00Z"}, "projects": []}`), &doc))
+		assert.Error(t, schema.Validate(doc))
+	})
+
+	t.Run("unknown finding class", func(t *testing.T) {
+		schema := compileSchema(t, "../schema/report.schema.json")
+		var doc interface{}
+		require.NoError(t, json.Unmarshal([]byte(`{"schemaVersion": "1.0.0", "metadata": {"tool": "trivyops", "toolVersion": "1.2.3", "generatedAt": "2024-01-03T00:00:00Z"}, "projects": [
+			{"id": 1, "name": "app", "trivyIgnore": [], "sources": [], "summary": {"vulnerabilities": 0, "critical": 0, "high": 0, "medium": 0, "low": 0, "unknown": 0, "secrets": 0, "misconfigurationsFailed": 0, "licenses": 0, "disallowedLicenses": 0},
+			 "findings": [{"class": "bug", "id": "1", "target": "go.mod", "severity": "LOW", "ignored": false}]}
+		]}`), &doc))
+		assert.Error(t, schema.Validate(doc))
+	})
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite holds the test cases of one project.
+type JUnitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase represents one scanned target of a project.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+}
+
+// JUnitFailure lists the findings which caused a test case to fail.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ToJUnit converts the results into a JUnit report. Every project is written
+// as testsuite and every target as testcase. A testcase fails if it has
+// vulnerabilities, failed misconfigurations, secrets or licenses with the
+// given severity or above.
+func (t TrivyResults) ToJUnit(severity string) (JUnitTestSuites, error) {
+	severity = strings.ToUpper(severity)
+	if severity != "UNKNOWN" && SeverityRank(severity) == 0 {
+		return JUnitTestSuites{}, fmt.Errorf("unknown severity %q, use one of [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]", severity)
+	}
+	threshold := SeverityRank(severity)
+
+	suites := JUnitTestSuites{Name: "trivyops"}
+	for _, result := range t {
+		suite := JUnitTestSuite{Name: result.ProjName}
+		for _, tgt := range result.ReportResult {
+			testCase := JUnitTestCase{Name: tgt.Target, ClassName: result.ProjName}
+			findings := junitFindings(tgt, threshold)
+			if len(findings) > 0 {
+				testCase.Failure = &JUnitFailure{
+					Message: fmt.Sprintf("%d findings with severity %s or above", len(findings), severity),
+					Type:    severity,
+					Text:    strings.Join(findings, "\n"),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+	}
+	return suites, nil
+}
+
+func junitFindings(tgt Result, threshold int) []string {
+	findings := []string{}
+	for _, v := range tgt.Vulnerabilities {
+		if SeverityRank(v.Severity) >= threshold {
+			findings = append(findings, fmt.Sprintf("%s %s %s (installed: %s, fixed: %s) %s", v.Severity, v.VulnerabilityID, v.PkgName, v.InstalledVersion, v.FixedVersion, v.Title))
+		}
+	}
+	for _, m := range tgt.Misconfigurations {
+		if m.Status == types.MisconfStatusFailure && SeverityRank(m.Severity) >= threshold {
+			findings = append(findings, fmt.Sprintf("%s %s %s %s", m.Severity, m.ID, MisconfLocation(tgt.Target, m), m.Message))
+		}
+	}
+	for _, s := range tgt.Secrets {
+		if SeverityRank(s.Severity) >= threshold {
+			findings = append(findings, fmt.Sprintf("%s %s %s:%d", s.Severity, s.RuleID, tgt.Target, s.StartLine))
+		}
+	}
+	for _, l := range tgt.Licenses {
+		if SeverityRank(l.Severity) >= threshold {
+			findings = append(findings, fmt.Sprintf("%s %s %s%s", l.Severity, l.Name, l.PkgName, l.FilePath))
+		}
+	}
+	return findings
+}
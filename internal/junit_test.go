@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"encoding/xml"
+	"testing"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJUnit(t *testing.T) {
+	vulli := func(id, severity string) types.DetectedVulnerability {
+		return types.DetectedVulnerability{VulnerabilityID: id, PkgName: "openssl", Vulnerability: dbtypes.Vulnerability{Severity: severity}}
+	}
+	trivyResults := TrivyResults{
+		&trivy{ProjName: "proj1", ReportResult: Results{
+			{Result: types.Result{Target: "app (debian 12.5)", Vulnerabilities: []types.DetectedVulnerability{vulli("CVE-1", "HIGH"), vulli("CVE-2", "LOW")}}},
+			{Result: types.Result{Target: "go.mod", Vulnerabilities: []types.DetectedVulnerability{vulli("CVE-3", "MEDIUM")}}},
+			{Result: types.Result{Target: "Dockerfile", Misconfigurations: []types.DetectedMisconfiguration{
+				{ID: "DS001", Severity: "CRITICAL", Status: types.MisconfStatusPassed},
+				{ID: "DS002", Severity: "HIGH", Status: types.MisconfStatusFailure},
+			}}},
+		}},
+		&trivy{ProjName: "proj2", ReportResult: Results{
+			{Result: types.Result{Target: ".env", Secrets: []types.DetectedSecret{{RuleID: "github-pat", Severity: "CRITICAL", StartLine: 2}}}},
+		}},
+	}
+
+	suites, err := trivyResults.ToJUnit("high")
+	require.NoError(t, err)
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 3, suites.Failures)
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "proj1", suites.Suites[0].Name)
+	assert.Equal(t, 3, suites.Suites[0].Tests)
+	assert.Equal(t, 2, suites.Suites[0].Failures)
+
+	appCase := suites.Suites[0].Cases[0]
+	assert.Equal(t, "app (debian 12.5)", appCase.Name)
+	assert.Equal(t, "proj1", appCase.ClassName)
+	require.NotNil(t, appCase.Failure)
+	assert.Equal(t, "1 findings with severity HIGH or above", appCase.Failure.Message)
+	assert.Contains(t, appCase.Failure.Text, "CVE-1")
+	assert.NotContains(t, appCase.Failure.Text, "CVE-2")
+	assert.Nil(t, suites.Suites[0].Cases[1].Failure)
+	require.NotNil(t, suites.Suites[0].Cases[2].Failure)
+	assert.Contains(t, suites.Suites[0].Cases[2].Failure.Text, "DS002")
+	assert.NotContains(t, suites.Suites[0].Cases[2].Failure.Text, "DS001")
+	require.NotNil(t, suites.Suites[1].Cases[0].Failure)
+	assert.Contains(t, suites.Suites[1].Cases[0].Failure.Text, "github-pat .env:2")
+
+	bt, err := xml.Marshal(suites)
+	require.NoError(t, err)
+	assert.Contains(t, string(bt), `<testsuites name="trivyops" tests="4" failures="3">`)
+	assert.Contains(t, string(bt), `<testcase name="go.mod" classname="proj1"></testcase>`)
+
+	suites, err = trivyResults.ToJUnit("low")
+	require.NoError(t, err)
+	assert.Equal(t, 4, suites.Failures)
+
+	_, err = trivyResults.ToJUnit("severe")
+	assert.EqualError(t, err, `unknown severity "SEVERE", use one of [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]`)
+}
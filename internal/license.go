@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"strings"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// License is a single license finding together with the target it was found
+// in. Disallowed is set by CheckLicenses if the license name is on the list of
+// disallowed licenses.
+type License struct {
+	Target     string
+	Provenance *Provenance `json:",omitempty"`
+	Disallowed bool
+	types.DetectedLicense
+}
+
+type licenses struct {
+	Count      int
+	Disallowed int
+	Category   map[string]int
+	Severity   map[string]int
+}
+
+// IsRestricted returns true if the license is disallowed or trivy categorized
+// it as forbidden or restricted.
+func (l License) IsRestricted() bool {
+	return l.Disallowed ||
+		l.Category == ftypes.CategoryForbidden ||
+		l.Category == ftypes.CategoryRestricted
+}
+
+// Location returns the package or file the license was found for.
+func (l License) Location() string {
+	if l.PkgName != "" {
+		return l.PkgName
+	}
+	return l.FilePath
+}
+
+// CheckLicenses marks all license findings which are on the disallowed list
+// (case insensitive) and returns the overall number of disallowed findings.
+func (t TrivyResults) CheckLicenses(disallowed []string) int {
+	count := 0
+	for _, result := range t {
+		result.Vulnerabilities.Licenses.Disallowed = 0
+		for i := range result.Licenses {
+			result.Licenses[i].Disallowed = isDisallowed(result.Licenses[i].Name, disallowed)
+			if result.Licenses[i].Disallowed {
+				result.Vulnerabilities.Licenses.Disallowed++
+			}
+		}
+		count += result.Vulnerabilities.Licenses.Disallowed
+	}
+	return count
+}
+
+func (r *trivy) collectLicenses() ([]License, licenses) {
+	found := []License{}
+	summary := licenses{
+		Category: map[string]int{},
+		Severity: map[string]int{},
+	}
+	for _, tgt := range r.ReportResult {
+		for _, l := range tgt.Licenses {
+			found = append(found, License{Target: tgt.Target, Provenance: tgt.Provenance, DetectedLicense: l})
+			summary.Count++
+			summary.Category[string(l.Category)]++
+			summary.Severity[l.Severity]++
+		}
+	}
+	return found, summary
+}
+
+func isDisallowed(name string, disallowed []string) bool {
+	for _, d := range disallowed {
+		if strings.EqualFold(strings.TrimSpace(d), name) {
+			return true
+		}
+	}
+	return false
+}
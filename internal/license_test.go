@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectLicenses(t *testing.T) {
+	result := &trivy{
+		ReportResult: Results{
+			{Result: types.Result{
+				Target: "OS Packages",
+				Licenses: []types.DetectedLicense{
+					{Name: "GPL-3.0", PkgName: "bash", Category: ftypes.CategoryRestricted, Severity: "HIGH"},
+					{Name: "MIT", PkgName: "musl", Category: ftypes.CategoryNotice, Severity: "LOW"},
+				},
+			}},
+			{Result: types.Result{
+				Target: "Loose File License(s)",
+				Licenses: []types.DetectedLicense{
+					{Name: "AGPL-3.0", FilePath: "LICENSE", Category: ftypes.CategoryForbidden, Severity: "CRITICAL"},
+				},
+			}},
+		},
+	}
+	result.check()
+	assert.Len(t, result.Licenses, 3)
+	assert.Equal(t, 3, result.Vulnerabilities.Licenses.Count)
+	assert.Equal(t, map[string]int{"restricted": 1, "notice": 1, "forbidden": 1}, result.Vulnerabilities.Licenses.Category)
+	assert.Equal(t, map[string]int{"HIGH": 1, "LOW": 1, "CRITICAL": 1}, result.Vulnerabilities.Licenses.Severity)
+	assert.Equal(t, 0, result.Vulnerabilities.Count)
+	assert.Equal(t, "bash", result.Licenses[0].Location())
+	assert.Equal(t, "LICENSE", result.Licenses[2].Location())
+	assert.True(t, result.Licenses[0].IsRestricted())
+	assert.False(t, result.Licenses[1].IsRestricted())
+	assert.True(t, result.Licenses[2].IsRestricted())
+}
+
+func TestCheckLicenses(t *testing.T) {
+	result := &trivy{
+		ReportResult: Results{
+			{Result: types.Result{
+				Licenses: []types.DetectedLicense{
+					{Name: "GPL-3.0", Category: ftypes.CategoryRestricted},
+					{Name: "MIT", Category: ftypes.CategoryNotice},
+					{Name: "WTFPL", Category: ftypes.CategoryUnknown},
+				},
+			}},
+		},
+	}
+	result.check()
+	trivyResults := TrivyResults{result}
+
+	assert.Equal(t, 0, trivyResults.CheckLicenses(nil))
+	assert.Equal(t, 2, trivyResults.CheckLicenses([]string{"gpl-3.0", " WTFPL"}))
+	assert.True(t, result.Licenses[0].Disallowed)
+	assert.False(t, result.Licenses[1].Disallowed)
+	assert.True(t, result.Licenses[2].Disallowed)
+	assert.True(t, result.Licenses[2].IsRestricted())
+	assert.Equal(t, 2, result.Vulnerabilities.Licenses.Disallowed)
+}
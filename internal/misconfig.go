@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+type misconfigurations struct {
+	Pass      int
+	Fail      int
+	Exception int
+}
+
+// GetMisconfSummary counts the misconfigurations by status.
+func GetMisconfSummary(dm []types.DetectedMisconfiguration) (pass, fail, exception int) {
+	for _, m := range dm {
+		switch m.Status {
+		case types.MisconfStatusPassed:
+			pass++
+		case types.MisconfStatusException:
+			exception++
+		default:
+			fail++
+		}
+	}
+	return pass, fail, exception
+}
+
+// MisconfLocation returns the file, lines and resource which caused the
+// misconfiguration.
+func MisconfLocation(target string, m types.DetectedMisconfiguration) string {
+	cause := m.CauseMetadata
+	location := target
+	if cause.StartLine > 0 {
+		if cause.EndLine > cause.StartLine {
+			location = fmt.Sprintf("%s:%d-%d", location, cause.StartLine, cause.EndLine)
+		} else {
+			location = fmt.Sprintf("%s:%d", location, cause.StartLine)
+		}
+	}
+	if cause.Resource != "" {
+		location = fmt.Sprintf("%s (%s)", location, cause.Resource)
+	}
+	return location
+}
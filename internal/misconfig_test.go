@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"testing"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMisconfSummary(t *testing.T) {
+	misconfs := []types.DetectedMisconfiguration{
+		{Status: types.MisconfStatusFailure},
+		{Status: types.MisconfStatusPassed},
+		{Status: types.MisconfStatusFailure},
+		{Status: types.MisconfStatusException},
+	}
+	pass, fail, exception := GetMisconfSummary(misconfs)
+	assert.Equal(t, 1, pass)
+	assert.Equal(t, 2, fail)
+	assert.Equal(t, 1, exception)
+}
+
+func TestMisconfLocation(t *testing.T) {
+	assert.Equal(t, "Dockerfile", MisconfLocation("Dockerfile", types.DetectedMisconfiguration{}))
+	assert.Equal(t, "Dockerfile:3", MisconfLocation("Dockerfile", types.DetectedMisconfiguration{
+		CauseMetadata: ftypes.CauseMetadata{StartLine: 3, EndLine: 3},
+	}))
+	assert.Equal(t, "main.tf:10-14 (aws_s3_bucket.logs)", MisconfLocation("main.tf", types.DetectedMisconfiguration{
+		CauseMetadata: ftypes.CauseMetadata{StartLine: 10, EndLine: 14, Resource: "aws_s3_bucket.logs"},
+	}))
+}
+
+func TestCheckMisconfigurations(t *testing.T) {
+	result := &trivy{
+		ReportResult: Results{
+			{Result: types.Result{
+				Target: "Dockerfile",
+				Misconfigurations: []types.DetectedMisconfiguration{
+					{ID: "DS002", Status: types.MisconfStatusFailure},
+					{ID: "DS001", Status: types.MisconfStatusPassed},
+				},
+			}},
+		},
+	}
+	result.check()
+	assert.Equal(t, 1, result.Vulnerabilities.Misconfigurations.Pass)
+	assert.Equal(t, 1, result.Vulnerabilities.Misconfigurations.Fail)
+	assert.Equal(t, 0, result.Vulnerabilities.Misconfigurations.Exception)
+}
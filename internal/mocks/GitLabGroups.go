@@ -21,6 +21,89 @@ func (_m *GitLabGroups) EXPECT() *GitLabGroups_Expecter {
 	return &GitLabGroups_Expecter{mock: &_m.Mock}
 }
 
+// GetGroup provides a mock function with given fields: gid, opt, options
+func (_m *GitLabGroups) GetGroup(gid interface{}, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error) {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, gid, opt)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroup")
+	}
+
+	var r0 *gitlab.Group
+	var r1 *gitlab.Response
+	var r2 error
+	if rf, ok := ret.Get(0).(func(interface{}, *gitlab.GetGroupOptions, ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error)); ok {
+		return rf(gid, opt, options...)
+	}
+	if rf, ok := ret.Get(0).(func(interface{}, *gitlab.GetGroupOptions, ...gitlab.RequestOptionFunc) *gitlab.Group); ok {
+		r0 = rf(gid, opt, options...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gitlab.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(interface{}, *gitlab.GetGroupOptions, ...gitlab.RequestOptionFunc) *gitlab.Response); ok {
+		r1 = rf(gid, opt, options...)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*gitlab.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(interface{}, *gitlab.GetGroupOptions, ...gitlab.RequestOptionFunc) error); ok {
+		r2 = rf(gid, opt, options...)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GitLabGroups_GetGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGroup'
+type GitLabGroups_GetGroup_Call struct {
+	*mock.Call
+}
+
+// GetGroup is a helper method to define mock.On call
+//   - gid interface{}
+//   - opt *gitlab.GetGroupOptions
+//   - options ...gitlab.RequestOptionFunc
+func (_e *GitLabGroups_Expecter) GetGroup(gid interface{}, opt interface{}, options ...interface{}) *GitLabGroups_GetGroup_Call {
+	return &GitLabGroups_GetGroup_Call{Call: _e.mock.On("GetGroup",
+		append([]interface{}{gid, opt}, options...)...)}
+}
+
+func (_c *GitLabGroups_GetGroup_Call) Run(run func(gid interface{}, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc)) *GitLabGroups_GetGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]gitlab.RequestOptionFunc, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(gitlab.RequestOptionFunc)
+			}
+		}
+		run(args[0].(interface{}), args[1].(*gitlab.GetGroupOptions), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *GitLabGroups_GetGroup_Call) Return(_a0 *gitlab.Group, _a1 *gitlab.Response, _a2 error) *GitLabGroups_GetGroup_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *GitLabGroups_GetGroup_Call) RunAndReturn(run func(interface{}, *gitlab.GetGroupOptions, ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error)) *GitLabGroups_GetGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListGroupProjects provides a mock function with given fields: gid, opt, options
 func (_m *GitLabGroups) ListGroupProjects(gid interface{}, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
 	_va := make([]interface{}, len(options))
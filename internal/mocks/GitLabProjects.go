@@ -21,6 +21,89 @@ func (_m *GitLabProjects) EXPECT() *GitLabProjects_Expecter {
 	return &GitLabProjects_Expecter{mock: &_m.Mock}
 }
 
+// GetProject provides a mock function with given fields: pid, opt, options
+func (_m *GitLabProjects) GetProject(pid interface{}, opt *gitlab.GetProjectOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error) {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, pid, opt)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProject")
+	}
+
+	var r0 *gitlab.Project
+	var r1 *gitlab.Response
+	var r2 error
+	if rf, ok := ret.Get(0).(func(interface{}, *gitlab.GetProjectOptions, ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error)); ok {
+		return rf(pid, opt, options...)
+	}
+	if rf, ok := ret.Get(0).(func(interface{}, *gitlab.GetProjectOptions, ...gitlab.RequestOptionFunc) *gitlab.Project); ok {
+		r0 = rf(pid, opt, options...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gitlab.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(interface{}, *gitlab.GetProjectOptions, ...gitlab.RequestOptionFunc) *gitlab.Response); ok {
+		r1 = rf(pid, opt, options...)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*gitlab.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(interface{}, *gitlab.GetProjectOptions, ...gitlab.RequestOptionFunc) error); ok {
+		r2 = rf(pid, opt, options...)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GitLabProjects_GetProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProject'
+type GitLabProjects_GetProject_Call struct {
+	*mock.Call
+}
+
+// GetProject is a helper method to define mock.On call
+//   - pid interface{}
+//   - opt *gitlab.GetProjectOptions
+//   - options ...gitlab.RequestOptionFunc
+func (_e *GitLabProjects_Expecter) GetProject(pid interface{}, opt interface{}, options ...interface{}) *GitLabProjects_GetProject_Call {
+	return &GitLabProjects_GetProject_Call{Call: _e.mock.On("GetProject",
+		append([]interface{}{pid, opt}, options...)...)}
+}
+
+func (_c *GitLabProjects_GetProject_Call) Run(run func(pid interface{}, opt *gitlab.GetProjectOptions, options ...gitlab.RequestOptionFunc)) *GitLabProjects_GetProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]gitlab.RequestOptionFunc, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(gitlab.RequestOptionFunc)
+			}
+		}
+		run(args[0].(interface{}), args[1].(*gitlab.GetProjectOptions), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *GitLabProjects_GetProject_Call) Return(_a0 *gitlab.Project, _a1 *gitlab.Response, _a2 error) *GitLabProjects_GetProject_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *GitLabProjects_GetProject_Call) RunAndReturn(run func(interface{}, *gitlab.GetProjectOptions, ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error)) *GitLabProjects_GetProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListProjects provides a mock function with given fields: opt, options
 func (_m *GitLabProjects) ListProjects(opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
 	_va := make([]interface{}, len(options))
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SortByName     = "name"
+	SortByCritical = "critical"
+	SortByHigh     = "high"
+	SortByTotal    = "total"
+	SortByFixable  = "fixable"
+	SortByAge      = "age"
+)
+
+const (
+	GroupByNamespace = "namespace"
+	GroupByLabel     = "label"
+	GroupByInstance  = "instance"
+	GroupByOS        = "os"
+	GroupByStatus    = "status"
+)
+
+const (
+	StatusCritical   = "critical"
+	StatusVulnerable = "vulnerable"
+	StatusEOSL       = "end of life"
+	StatusClean      = "clean"
+	StatusNoResults  = "no results"
+)
+
+var statusRank = map[string]int{
+	StatusCritical:   0,
+	StatusVulnerable: 1,
+	StatusEOSL:       2,
+	StatusClean:      3,
+	StatusNoResults:  4,
+}
+
+// sortFuncs compare two projects. A negative result sorts a before b.
+var sortFuncs = map[string]func(a, b *trivy) int{
+	SortByName: func(a, b *trivy) int { return 0 },
+	SortByCritical: func(a, b *trivy) int {
+		return b.Vulnerabilities.Critical - a.Vulnerabilities.Critical
+	},
+	SortByHigh: func(a, b *trivy) int {
+		return b.Vulnerabilities.High - a.Vulnerabilities.High
+	},
+	SortByTotal: func(a, b *trivy) int {
+		return b.Vulnerabilities.Count - a.Vulnerabilities.Count
+	},
+	SortByFixable: func(a, b *trivy) int {
+		return b.fixable() - a.fixable()
+	},
+	SortByAge: func(a, b *trivy) int {
+		ta, tb := a.lastScan(), b.lastScan()
+		switch {
+		case ta.Equal(tb):
+			return 0
+		case ta.IsZero():
+			return 1
+		case tb.IsZero():
+			return -1
+		case ta.Before(tb):
+			return -1
+		default:
+			return 1
+		}
+	},
+}
+
+var groupFuncs = map[string]func(r *trivy) string{
+	GroupByNamespace: func(r *trivy) string { return r.ProjNamespace },
+	GroupByLabel:     func(r *trivy) string { return r.Label },
+	GroupByInstance:  func(r *trivy) string { return r.Instance },
+	GroupByOS: func(r *trivy) string {
+		for _, report := range r.Reports {
+			if report.Metadata.OS != nil && report.Metadata.OS.Family != "" {
+				return string(report.Metadata.OS.Family)
+			}
+		}
+		return "unknown"
+	},
+	GroupByStatus: func(r *trivy) string { return r.Status() },
+}
+
+// Order sorts the results by the given key and groups them if groupBy is not
+// empty. The group is stored in the Group field of every project. Projects
+// with the same key are ordered by namespace, name and ID so that the order
+// is always deterministic. Age sorts the projects with the oldest scan first.
+func (t TrivyResults) Order(sortBy, groupBy string) error {
+	sortBy = strings.ToLower(sortBy)
+	if sortBy == "" {
+		sortBy = SortByName
+	}
+	compare, ok := sortFuncs[sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q, use one of [%s]", sortBy, strings.Join(sortKeys(), ", "))
+	}
+	groupBy = strings.ToLower(groupBy)
+	for _, result := range t {
+		result.Group = ""
+	}
+	if groupBy != "" {
+		group, ok := groupFuncs[groupBy]
+		if !ok {
+			return fmt.Errorf("unknown group key %q, use one of [%s, %s, %s, %s, %s]", groupBy, GroupByNamespace, GroupByLabel, GroupByInstance, GroupByOS, GroupByStatus)
+		}
+		for _, result := range t {
+			result.Group = group(result)
+		}
+	}
+
+	sort.SliceStable(t, func(i, j int) bool {
+		a, b := t[i], t[j]
+		if a.Group != b.Group {
+			if groupBy == GroupByStatus {
+				return statusRank[a.Group] < statusRank[b.Group]
+			}
+			return a.Group < b.Group
+		}
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		if a.ProjNamespace != b.ProjNamespace {
+			return a.ProjNamespace < b.ProjNamespace
+		}
+		if a.ProjName != b.ProjName {
+			return a.ProjName < b.ProjName
+		}
+		return a.ProjId < b.ProjId
+	})
+	return nil
+}
+
+// ResultGroup are consecutive projects of the ordered results with the same
+// group.
+type ResultGroup struct {
+	Name    string
+	Results TrivyResults
+}
+
+// Groups splits the ordered results into their groups. Results which aren't
+// grouped are returned as one group without name.
+func (t TrivyResults) Groups() []ResultGroup {
+	groups := []ResultGroup{}
+	for i, result := range t {
+		if i == 0 || t[i-1].Group != result.Group {
+			groups = append(groups, ResultGroup{Name: result.Group})
+		}
+		groups[len(groups)-1].Results = append(groups[len(groups)-1].Results, result)
+	}
+	return groups
+}
+
+func sortKeys() []string {
+	return []string{SortByName, SortByCritical, SortByHigh, SortByTotal, SortByFixable, SortByAge}
+}
+
+// Status returns a short status of the project which is used to group
+// projects.
+func (r *trivy) Status() string {
+	switch {
+	case len(r.Reports) == 0 && len(r.ReportResult) == 0:
+		return StatusNoResults
+	case r.Vulnerabilities.Critical > 0:
+		return StatusCritical
+	case r.Vulnerabilities.Count > 0 || r.Vulnerabilities.Licenses.Count > 0:
+		return StatusVulnerable
+	case r.hasEOSL():
+		return StatusEOSL
+	default:
+		return StatusClean
+	}
+}
+
+func (r *trivy) fixable() int {
+	fixable := 0
+	for _, tgt := range r.ReportResult {
+		for _, v := range tgt.Vulnerabilities {
+			if v.FixedVersion != "" {
+				fixable++
+			}
+		}
+	}
+	return fixable
+}
+
+// lastScan returns the time of the newest report of the project.
+func (r *trivy) lastScan() time.Time {
+	last := time.Time{}
+	for _, report := range r.Reports {
+		if report.Provenance != nil && report.Provenance.CreatedAt.After(last) {
+			last = report.Provenance.CreatedAt
+		}
+	}
+	return last
+}
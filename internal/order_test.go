@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder(t *testing.T) {
+	vulli := func(severity, fixed string) types.DetectedVulnerability {
+		return types.DetectedVulnerability{FixedVersion: fixed, Vulnerability: dbtypes.Vulnerability{Severity: severity}}
+	}
+	report := func(family string, created time.Time) []Report {
+		return []Report{{
+			Provenance: &Provenance{CreatedAt: created},
+			Metadata:   types.Metadata{OS: &ftypes.OS{Family: ftypes.OSType(family)}},
+		}}
+	}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	newResults := func() TrivyResults {
+		results := TrivyResults{
+			&trivy{ProjId: 4, ProjName: "delta", ProjNamespace: "b", Label: "platform", Reports: report("debian", day(4)), ReportResult: Results{
+				{Result: types.Result{Vulnerabilities: []types.DetectedVulnerability{vulli("HIGH", "1"), vulli("HIGH", "1"), vulli("LOW", "")}}},
+			}},
+			&trivy{ProjId: 1, ProjName: "alpha", ProjNamespace: "a", Label: "apps", Reports: report("alpine", day(2)), ReportResult: Results{
+				{Result: types.Result{Vulnerabilities: []types.DetectedVulnerability{vulli("CRITICAL", "")}}},
+			}},
+			&trivy{ProjId: 3, ProjName: "charlie", ProjNamespace: "b", Instance: "internal", Label: "platform"},
+			&trivy{ProjId: 2, ProjName: "bravo", ProjNamespace: "a", Reports: report("alpine", day(1)), ReportResult: Results{
+				{Result: types.Result{Target: "go.mod"}},
+			}},
+		}
+		results.Check()
+		return results
+	}
+	ids := func(results TrivyResults) []int {
+		ids := []int{}
+		for _, r := range results {
+			ids = append(ids, r.ProjId)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		sortBy  string
+		groupBy string
+		ids     []int
+		groups  []string
+	}{
+		{sortBy: "", ids: []int{1, 2, 3, 4}},
+		{sortBy: SortByName, ids: []int{1, 2, 3, 4}},
+		{sortBy: SortByCritical, ids: []int{1, 2, 3, 4}},
+		{sortBy: SortByHigh, ids: []int{4, 1, 2, 3}},
+		{sortBy: SortByTotal, ids: []int{4, 1, 2, 3}},
+		{sortBy: SortByFixable, ids: []int{4, 1, 2, 3}},
+		{sortBy: SortByAge, ids: []int{2, 1, 4, 3}},
+		{sortBy: SortByTotal, groupBy: GroupByNamespace, ids: []int{1, 2, 4, 3}, groups: []string{"a", "a", "b", "b"}},
+		{sortBy: SortByName, groupBy: GroupByLabel, ids: []int{2, 1, 3, 4}, groups: []string{"", "apps", "platform", "platform"}},
+		{sortBy: SortByName, groupBy: GroupByInstance, ids: []int{1, 2, 4, 3}, groups: []string{"", "", "", "internal"}},
+		{sortBy: SortByName, groupBy: GroupByOS, ids: []int{1, 2, 4, 3}, groups: []string{"alpine", "alpine", "debian", "unknown"}},
+		{sortBy: SortByName, groupBy: GroupByStatus, ids: []int{1, 4, 2, 3}, groups: []string{StatusCritical, StatusVulnerable, StatusClean, StatusNoResults}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy+"/"+tt.groupBy, func(t *testing.T) {
+			results := newResults()
+			assert.NoError(t, results.Order(tt.sortBy, tt.groupBy))
+			assert.Equal(t, tt.ids, ids(results))
+			if tt.groups != nil {
+				for i, r := range results {
+					assert.Equal(t, tt.groups[i], r.Group)
+				}
+			}
+		})
+	}
+
+	t.Run("groups", func(t *testing.T) {
+		results := newResults()
+		assert.NoError(t, results.Order(SortByName, GroupByNamespace))
+		groups := results.Groups()
+		assert.Len(t, groups, 2)
+		assert.Equal(t, "a", groups[0].Name)
+		assert.Equal(t, []int{1, 2}, ids(groups[0].Results))
+		assert.Equal(t, "b", groups[1].Name)
+		assert.Equal(t, []int{3, 4}, ids(groups[1].Results))
+
+		assert.NoError(t, results.Order(SortByName, ""))
+		groups = results.Groups()
+		assert.Len(t, groups, 1)
+		assert.Equal(t, "", groups[0].Name)
+		assert.Equal(t, []int{1, 2, 3, 4}, ids(groups[0].Results))
+		assert.Empty(t, TrivyResults{}.Groups())
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		results := newResults()
+		assert.EqualError(t, results.Order("size", ""), `unknown sort key "size", use one of [name, critical, high, total, fixable, age]`)
+		assert.EqualError(t, results.Order("name", "team"), `unknown group key "team", use one of [namespace, label, instance, os, status]`)
+	})
+}
@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	RulePath         = "path"
+	RuleTopic        = "topic"
+	RuleVisibility   = "visibility"
+	RuleActiveWithin = "active-within"
+	RuleInactiveFor  = "inactive-for"
+)
+
+var ruleKeys = []string{RulePath, RuleTopic, RuleVisibility, RuleActiveWithin, RuleInactiveFor}
+
+var visibilities = []string{
+	string(gitlab.PublicVisibility),
+	string(gitlab.InternalVisibility),
+	string(gitlab.PrivateVisibility),
+}
+
+// now is replaced in tests to get a stable last activity.
+var now = time.Now
+
+// ProjectRules decide which projects are scanned. A project is scanned if it
+// matches at least one include rule (or no include rule is given) and no
+// exclude rule. The rules only use the project list, so they are applied
+// before any job, artifact or file is requested.
+type ProjectRules struct {
+	Include []ProjectRule
+	Exclude []ProjectRule
+}
+
+// ProjectRule matches a project if all of its conditions match. A rule is
+// written as comma separated key=value conditions e.g.
+// "path=^group/team/,topic=docker,active-within=90d". A rule without key is a
+// path regex and may contain commas.
+type ProjectRule struct {
+	Definition   string
+	Path         *regexp.Regexp
+	Topics       []string
+	Visibility   []string
+	ActiveWithin time.Duration
+	InactiveFor  time.Duration
+}
+
+// NewProjectRules parses the include and exclude rules.
+func NewProjectRules(include, exclude []string) (*ProjectRules, error) {
+	rules := &ProjectRules{}
+	for _, definition := range include {
+		rule, err := parseProjectRule(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include rule %q: %w", definition, err)
+		}
+		rules.Include = append(rules.Include, rule)
+	}
+	for _, definition := range exclude {
+		rule, err := parseProjectRule(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude rule %q: %w", definition, err)
+		}
+		rules.Exclude = append(rules.Exclude, rule)
+	}
+	return rules, nil
+}
+
+func parseProjectRule(definition string) (ProjectRule, error) {
+	rule := ProjectRule{Definition: strings.TrimSpace(definition)}
+	if rule.Definition == "" {
+		return rule, fmt.Errorf("rule is empty")
+	}
+	key, _, _ := strings.Cut(rule.Definition, "=")
+	if !contains(ruleKeys, strings.ToLower(strings.TrimSpace(key))) {
+		re, err := regexp.Compile(rule.Definition)
+		if err != nil {
+			return rule, fmt.Errorf("%s is not a valid regex: %v", rule.Definition, err)
+		}
+		rule.Path = re
+		return rule, nil
+	}
+
+	for _, condition := range strings.Split(rule.Definition, ",") {
+		key, value, found := strings.Cut(condition, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !found || value == "" {
+			return rule, fmt.Errorf("condition %q must be key=value", condition)
+		}
+		var err error
+		switch key {
+		case RulePath:
+			if rule.Path, err = regexp.Compile(value); err != nil {
+				return rule, fmt.Errorf("%s is not a valid regex: %v", value, err)
+			}
+		case RuleTopic:
+			rule.Topics = append(rule.Topics, strings.ToLower(value))
+		case RuleVisibility:
+			value = strings.ToLower(value)
+			if !contains(visibilities, value) {
+				return rule, fmt.Errorf("unknown visibility %q, use one of [%s]", value, strings.Join(visibilities, ", "))
+			}
+			rule.Visibility = append(rule.Visibility, value)
+		case RuleActiveWithin:
+			if rule.ActiveWithin, err = parseAge(value); err != nil {
+				return rule, err
+			}
+		case RuleInactiveFor:
+			if rule.InactiveFor, err = parseAge(value); err != nil {
+				return rule, err
+			}
+		default:
+			return rule, fmt.Errorf("unknown key %q, use one of [%s]", key, strings.Join(ruleKeys, ", "))
+		}
+	}
+	return rule, nil
+}
+
+// parseAge parses a go duration which additionally supports days (e.g. 90d).
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		d, err := strconv.Atoi(days)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("%s is not a valid number of days", value)
+		}
+		return time.Duration(d) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s is not a valid duration", value)
+	}
+	return d, nil
+}
+
+// Match returns true if the project is scanned and the reason if not.
+func (r *ProjectRules) Match(proj *gitlab.Project) (bool, string) {
+	if r == nil {
+		return true, ""
+	}
+	if len(r.Include) > 0 {
+		included := false
+		for _, rule := range r.Include {
+			if rule.Match(proj) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false, "no include rule matches"
+		}
+	}
+	for _, rule := range r.Exclude {
+		if rule.Match(proj) {
+			return false, fmt.Sprintf("excluded by %s", rule.Definition)
+		}
+	}
+	return true, ""
+}
+
+// Match returns true if all conditions of the rule match the project. Topics
+// are compared case insensitive. Projects without last activity never match
+// active-within and inactive-for.
+func (r ProjectRule) Match(proj *gitlab.Project) bool {
+	if r.Path != nil && !r.Path.MatchString(proj.PathWithNamespace) {
+		return false
+	}
+	for _, topic := range r.Topics {
+		if !hasTopic(proj, topic) {
+			return false
+		}
+	}
+	if len(r.Visibility) > 0 && !contains(r.Visibility, string(proj.Visibility)) {
+		return false
+	}
+	if r.ActiveWithin > 0 || r.InactiveFor > 0 {
+		if proj.LastActivityAt == nil {
+			return false
+		}
+		inactive := now().Sub(*proj.LastActivityAt)
+		if r.ActiveWithin > 0 && inactive > r.ActiveWithin {
+			return false
+		}
+		if r.InactiveFor > 0 && inactive < r.InactiveFor {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTopic(proj *gitlab.Project, topic string) bool {
+	for _, t := range proj.Topics {
+		if strings.ToLower(t) == topic {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectRules(t *testing.T) {
+	current := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+
+	recent := current.Add(-48 * time.Hour)
+	old := current.AddDate(-2, 0, 0)
+	projs := []*gitlab.Project{
+		{PathWithNamespace: "group/team/app", Visibility: gitlab.PrivateVisibility, LastActivityAt: &recent},
+		{PathWithNamespace: "group/team/old", Visibility: gitlab.PrivateVisibility, LastActivityAt: &old},
+		{PathWithNamespace: "group/other/svc", Visibility: gitlab.InternalVisibility, Topics: []string{"Docker", "go"}, LastActivityAt: &recent},
+		{PathWithNamespace: "group/other/web", Visibility: gitlab.PublicVisibility, Topics: []string{"docker"}, LastActivityAt: &recent},
+		{PathWithNamespace: "group/other/lib", Visibility: gitlab.PrivateVisibility},
+	}
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		selected []string
+	}{
+		{name: "no rules", selected: []string{"group/team/app", "group/team/old", "group/other/svc", "group/other/web", "group/other/lib"}},
+		{name: "path regex", include: []string{"^group/team/"}, selected: []string{"group/team/app", "group/team/old"}},
+		{name: "several includes", include: []string{"path=^group/team/", "topic=docker"}, selected: []string{"group/team/app", "group/team/old", "group/other/svc", "group/other/web"}},
+		{name: "all conditions of a rule", include: []string{"topic=docker,visibility=internal"}, selected: []string{"group/other/svc"}},
+		{name: "exclude visibility", exclude: []string{"visibility=public", "visibility=internal"}, selected: []string{"group/team/app", "group/team/old", "group/other/lib"}},
+		{name: "exclude inactive", exclude: []string{"inactive-for=365d"}, selected: []string{"group/team/app", "group/other/svc", "group/other/web", "group/other/lib"}},
+		{name: "active within", include: []string{"active-within=72h"}, selected: []string{"group/team/app", "group/other/svc", "group/other/web"}},
+		{name: "include and exclude", include: []string{"^group/other/"}, exclude: []string{"path=lib$", "visibility=public"}, selected: []string{"group/other/svc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewProjectRules(tt.include, tt.exclude)
+			require.NoError(t, err)
+			selected := []string{}
+			for _, proj := range (Scan{Rules: rules}).SelectProjects(projs) {
+				selected = append(selected, proj.PathWithNamespace)
+			}
+			assert.Equal(t, tt.selected, selected)
+		})
+	}
+
+	t.Run("reason", func(t *testing.T) {
+		rules, err := NewProjectRules([]string{"^group/"}, []string{"inactive-for=1y", "inactive-for=365d"})
+		assert.Nil(t, rules)
+		assert.EqualError(t, err, `invalid exclude rule "inactive-for=1y": 1y is not a valid duration`)
+
+		rules, err = NewProjectRules([]string{"^group/team/"}, []string{"inactive-for=365d"})
+		require.NoError(t, err)
+		ok, reason := rules.Match(projs[1])
+		assert.False(t, ok)
+		assert.Equal(t, "excluded by inactive-for=365d", reason)
+		ok, reason = rules.Match(projs[2])
+		assert.False(t, ok)
+		assert.Equal(t, "no include rule matches", reason)
+	})
+
+	t.Run("legacy filter", func(t *testing.T) {
+		s, err := InitScanner("1", "job", "artifact", "svc|web", nil)
+		require.NoError(t, err)
+		projs[2].NameWithNamespace = "group / other / svc"
+		s.Rules, err = NewProjectRules(nil, []string{"visibility=public"})
+		require.NoError(t, err)
+		assert.Equal(t, []*gitlab.Project{projs[2]}, s.SelectProjects(projs))
+	})
+}
+
+func TestNewProjectRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		err  string
+	}{
+		{name: "empty", rule: " ", err: `invalid include rule " ": rule is empty`},
+		{name: "invalid regex", rule: "path=^(group", err: "invalid include rule \"path=^(group\": ^(group is not a valid regex: error parsing regexp: missing closing ): `^(group`"},
+		{name: "unknown key", rule: "path=^group/,owner=me", err: `invalid include rule "path=^group/,owner=me": unknown key "owner", use one of [path, topic, visibility, active-within, inactive-for]`},
+		{name: "missing value", rule: "topic=", err: `invalid include rule "topic=": condition "topic=" must be key=value`},
+		{name: "unknown visibility", rule: "visibility=secret", err: `invalid include rule "visibility=secret": unknown visibility "secret", use one of [public, internal, private]`},
+		{name: "invalid days", rule: "active-within=-3d", err: `invalid include rule "active-within=-3d": -3d is not a valid number of days`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProjectRules([]string{tt.rule}, nil)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
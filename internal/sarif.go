@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+const (
+	sarifToolName       = "trivyops"
+	sarifInformationURI = "https://github.com/steffakasid/trivyops"
+)
+
+// ToSarif converts the results into a SARIF 2.1.0 report. Every project is
+// written as a separate run, the rules are taken from the vulnerability IDs.
+func (t TrivyResults) ToSarif(version string) (*sarif.Report, error) {
+	report, err := sarif.New(sarif.Version210)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range t {
+		run := sarif.NewRunWithInformationURI(sarifToolName, sarifInformationURI)
+		run.Tool.Driver.Version = &version
+		run.WithAutomationDetails(sarif.NewRunAutomationDetails().WithID(sarifAutomationID(result)))
+		run.Properties = sarif.Properties{
+			"project":   result.ProjName,
+			"projectId": result.ProjId,
+		}
+		if result.Instance != "" {
+			run.Properties["instance"] = result.Instance
+		}
+		if result.Label != "" {
+			run.Properties["label"] = result.Label
+		}
+
+		for _, tgt := range result.ReportResult {
+			for _, vulli := range tgt.Vulnerabilities {
+				addSarifRule(run, vulli)
+				addSarifResult(run, tgt, vulli)
+			}
+		}
+		report.AddRun(run)
+	}
+	return report, nil
+}
+
+// sarifAutomationID identifies the run of a project. The instance is part of
+// the ID because project IDs are only unique per instance.
+func sarifAutomationID(result *trivy) string {
+	if result.Instance == "" {
+		return fmt.Sprintf("%s/%d", result.ProjName, result.ProjId)
+	}
+	return fmt.Sprintf("%s/%s/%d", result.Instance, result.ProjName, result.ProjId)
+}
+
+func addSarifRule(run *sarif.Run, vulli types.DetectedVulnerability) {
+	rule := run.AddRule(vulli.VulnerabilityID).
+		WithName(vulli.VulnerabilityID).
+		WithShortDescription(sarif.NewMultiformatMessageString(firstNonEmpty(vulli.Title, vulli.VulnerabilityID))).
+		WithFullDescription(sarif.NewMultiformatMessageString(firstNonEmpty(vulli.Description, vulli.Title, vulli.VulnerabilityID))).
+		WithTextHelp(fmt.Sprintf("Vulnerability %s\nSeverity: %s\n%s", vulli.VulnerabilityID, vulli.Severity, vulli.PrimaryURL))
+	if vulli.PrimaryURL != "" {
+		rule.WithHelpURI(vulli.PrimaryURL)
+	}
+	rule.WithProperties(sarif.Properties{
+		"tags":              []string{"vulnerability", "security", vulli.Severity},
+		"precision":         "very-high",
+		"security-severity": fmt.Sprintf("%.1f", securitySeverity(vulli)),
+	})
+}
+
+func addSarifResult(run *sarif.Run, tgt Result, vulli types.DetectedVulnerability) {
+	message := fmt.Sprintf("Package: %s\nInstalled Version: %s\nVulnerability %s\nSeverity: %s\nFixed Version: %s",
+		vulli.PkgName, vulli.InstalledVersion, vulli.VulnerabilityID, vulli.Severity, vulli.FixedVersion)
+	if vulli.PrimaryURL != "" {
+		message += fmt.Sprintf("\nLink: %s", vulli.PrimaryURL)
+	}
+
+	location := sarif.NewLocationWithPhysicalLocation(
+		sarif.NewPhysicalLocation().
+			WithArtifactLocation(sarif.NewSimpleArtifactLocation(sarifURI(tgt.Target))).
+			WithRegion(sarif.NewSimpleRegion(1, 1)),
+	)
+	location.AddLogicalLocations(sarif.NewLogicalLocation().
+		WithName(vulli.PkgName).
+		WithFullyQualifiedName(fmt.Sprintf("%s@%s", vulli.PkgName, vulli.InstalledVersion)).
+		WithKind("package"))
+
+	run.CreateResultForRule(vulli.VulnerabilityID).
+		WithLevel(sarifLevel(vulli.Severity)).
+		WithMessage(sarif.NewTextMessage(message)).
+		AddLocation(location)
+}
+
+func sarifLevel(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL", "HIGH":
+		return "error"
+	case "MEDIUM":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity returns the CVSS score or a score derived from the
+// severity if no CVSS score is available.
+func securitySeverity(vulli types.DetectedVulnerability) float64 {
+	if score := CVSSScore(vulli); score > 0 {
+		return score
+	}
+	switch strings.ToUpper(vulli.Severity) {
+	case "CRITICAL":
+		return 9.5
+	case "HIGH":
+		return 8.0
+	case "MEDIUM":
+		return 5.5
+	case "LOW":
+		return 2.0
+	default:
+		return 0.0
+	}
+}
+
+func sarifURI(target string) string {
+	return (&url.URL{Path: target}).String()
+}
+
+func firstNonEmpty(strs ...string) string {
+	for _, str := range strs {
+		if str != "" {
+			return str
+		}
+	}
+	return ""
+}
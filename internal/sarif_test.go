@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSarif(t *testing.T) {
+	bt, err := os.ReadFile("../test/trivy-result.json")
+	require.NoError(t, err)
+	report, err := Scan{}.reportFromFile(bt)
+	require.NoError(t, err)
+
+	results := Results{}
+	for _, res := range report.Results {
+		results = append(results, Result{Result: res})
+	}
+	trivyResults := TrivyResults{
+		&trivy{ProjId: 1, ProjName: "proj1", ReportResult: results},
+		&trivy{ProjId: 2, ProjName: "proj2", Instance: "internal", Label: "platform", ReportResult: Results{
+			{Result: types.Result{
+				Target: "app (debian 12.5)",
+				Vulnerabilities: []types.DetectedVulnerability{
+					{
+						VulnerabilityID:  "CVE-2024-0001",
+						PkgName:          "openssl",
+						InstalledVersion: "3.0.0",
+						PrimaryURL:       "https://avd.aquasec.com/nvd/cve-2024-0001",
+						Vulnerability: dbtypes.Vulnerability{
+							Severity: "CRITICAL",
+							CVSS:     dbtypes.VendorCVSS{"nvd": {V2Score: 10, V3Score: 9.8}},
+						},
+					},
+				},
+			}},
+		}},
+	}
+	trivyResults.Check()
+
+	sarifReport, err := trivyResults.ToSarif("1.0.0")
+	require.NoError(t, err)
+	assert.Len(t, sarifReport.Runs, 2)
+	assert.Len(t, sarifReport.Runs[0].Results, trivyResults[0].Vulnerabilities.Count)
+	assert.Len(t, sarifReport.Runs[1].Tool.Driver.Rules, 1)
+	assert.Equal(t, "error", *sarifReport.Runs[1].Results[0].Level)
+	assert.Equal(t, "app%20%28debian%2012.5%29", *sarifReport.Runs[1].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "9.8", sarifReport.Runs[1].Tool.Driver.Rules[0].Properties["security-severity"])
+	assert.Equal(t, "proj1/1", *sarifReport.Runs[0].AutomationDetails.ID)
+	assert.NotContains(t, sarifReport.Runs[0].Properties, "instance")
+	assert.Equal(t, "internal/proj2/2", *sarifReport.Runs[1].AutomationDetails.ID)
+	assert.Equal(t, "internal", sarifReport.Runs[1].Properties["instance"])
+	assert.Equal(t, "platform", sarifReport.Runs[1].Properties["label"])
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, sarifReport.PrettyWrite(buf))
+	assertValidSarif(t, buf.Bytes())
+}
+
+func assertValidSarif(t *testing.T, bt []byte) {
+	assertValidJSON(t, "../test/sarif-schema-2.1.0.json", bt)
+}
+
+func assertValidJSON(t *testing.T, schemaPath string, bt []byte) {
+	schema := compileSchema(t, schemaPath)
+	var doc interface{}
+	require.NoError(t, json.Unmarshal(bt, &doc))
+	assert.NoError(t, schema.Validate(doc))
+}
+
+func compileSchema(t *testing.T, schemaPath string) *jsonschema.Schema {
+	schemaFile, err := os.Open(schemaPath)
+	require.NoError(t, err)
+	defer schemaFile.Close()
+	schemaDoc, err := jsonschema.UnmarshalJSON(schemaFile)
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource(filepath.Base(schemaPath), schemaDoc))
+	schema, err := compiler.Compile(filepath.Base(schemaPath))
+	require.NoError(t, err)
+	return schema
+}
@@ -18,10 +18,15 @@ const (
 
 type Scan struct {
 	ID               string
+	Instance         string
 	GitLabClient     *GitLabClient
 	JobName          string
 	ArtifactFileName string
+	Targets          []Target
+	Sources          map[int]Target
 	Filter           *regexp.Regexp
+	Rules            *ProjectRules
+	FindingFilter    *FindingFilter
 }
 
 func InitScanner(id, jobname, artifactFileName, filter string, gitLabClient *GitLabClient) (*Scan, error) {
@@ -38,73 +43,149 @@ func InitScanner(id, jobname, artifactFileName, filter string, gitLabClient *Git
 	return &Scan{ID: id, GitLabClient: gitLabClient, JobName: jobname, ArtifactFileName: artifactFileName, Filter: reFilter}, nil
 }
 
+// ListProjects lists the projects of all targets and remembers the target of
+// every project. Without targets all projects of the user are listed.
+func (s *Scan) ListProjects() ([]*gitlab.Project, error) {
+	if len(s.Targets) == 0 {
+		return s.GitLabClient.GetProjects(s.ID)
+	}
+	projs, sources, err := s.GitLabClient.GetTargetProjects(s.Targets)
+	if err != nil {
+		return nil, err
+	}
+	s.Sources = sources
+	return projs, nil
+}
+
+// Selects returns true if the project matches the filter and the project
+// rules and is scanned. Otherwise the reason is returned.
+func (s Scan) Selects(proj *gitlab.Project) (bool, string) {
+	if s.Filter != nil && !s.Filter.MatchString(proj.NameWithNamespace) {
+		return false, "filtered out"
+	}
+	return s.Rules.Match(proj)
+}
+
+// SelectProjects returns the projects which are scanned. It doesn't call the
+// GitLab API.
+func (s Scan) SelectProjects(projs []*gitlab.Project) []*gitlab.Project {
+	selected := []*gitlab.Project{}
+	for _, proj := range projs {
+		if ok, reason := s.Selects(proj); ok {
+			selected = append(selected, proj)
+		} else {
+			logger.WithField("Project", proj.PathWithNamespace).Debugln(reason)
+		}
+	}
+	return selected
+}
+
 func (s Scan) ScanProjects(projs []*gitlab.Project) (TrivyResults, error) {
+	results := TrivyResults{}
+	err := s.StreamProjects(projs, func(result TrivyResults) error {
+		results = append(results, result...)
+		return nil
+	})
+	return results, err
+}
 
+// StreamProjects scans the projects like ScanProjects but passes every
+// checked project to the handler as soon as it's scanned instead of
+// collecting all results. The handler is never called concurrently. If the
+// handler returns an error, the scan is cancelled and the error is returned.
+func (s Scan) StreamProjects(projs []*gitlab.Project, handler func(result TrivyResults) error) error {
+
+	projs = s.SelectProjects(projs)
 	var wg sync.WaitGroup
 	projectResults := make(chan *trivy)
+	done := make(chan struct{})
 	for i := 0; i < len(projs); i += chunkSize {
-		if (i + chunkSize) > len(projs) {
-			go s.scanProjects(projs[i:len(projs)-1], projectResults, &wg)
-		} else {
-			go s.scanProjects(projs[i:i+chunkSize], projectResults, &wg)
-		}
 		wg.Add(1)
+		go s.scanProjects(projs[i:min(i+chunkSize, len(projs))], projectResults, done, &wg)
 	}
-	resultsChannel := make(chan TrivyResults)
-	go s.processResults(projectResults, resultsChannel)
+	errChannel := make(chan error)
+	go s.processResults(projectResults, handler, done, errChannel)
 	wg.Wait()
 	close(projectResults)
-	results := <-resultsChannel
-	return results, nil
+	return <-errChannel
 }
 
-func (s Scan) scanProjects(projs []*gitlab.Project, channel chan *trivy, wg *sync.WaitGroup) {
+// scanProjects sends the result of every project to the channel. It stops
+// scanning as soon as done is closed.
+func (s Scan) scanProjects(projs []*gitlab.Project, channel chan *trivy, done chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
 
 	for _, proj := range projs {
-		if s.Filter == nil || len(s.Filter.FindAllString(proj.NameWithNamespace, -1)) > 0 {
-			logger.Infof("Scan project %s for trivy results\n", proj.NameWithNamespace)
+		select {
+		case <-done:
+			return
+		default:
+		}
+		logger.Infof("Scan project %s for trivy results\n", proj.NameWithNamespace)
 
-			projResult := &trivy{
-				ProjId:   proj.ID,
-				ProjName: proj.Name,
-			}
-			jobList, err := s.getTrivyJob(s.JobName, projResult.ProjId)
-			logIfError(proj.Name, err)
+		source := s.Sources[proj.ID]
+		projResult := &trivy{
+			ProjId:   proj.ID,
+			ProjName: proj.Name,
+			Instance: s.Instance,
+			Label:    source.Label,
+			Archived: proj.Archived,
+			Fork:     proj.ForkedFromProject != nil,
+			Shared:   source.shares(proj),
+		}
+		if proj.Namespace != nil {
+			projResult.ProjNamespace = proj.Namespace.FullPath
+		}
+		jobList, err := s.getTrivyJob(s.JobName, projResult.ProjId)
+		logIfError(proj.Name, err)
 
-			resultsList := types.Results{}
-			for _, job := range jobList {
-				results, err := s.getTrivyResult(s.ArtifactFileName, job)
-				logIfError(proj.Name, err)
-				if results != nil {
-					resultsList = append(resultsList, results...)
+		resultsList := Results{}
+		for _, job := range jobList {
+			report, err := s.getTrivyResult(s.ArtifactFileName, job)
+			logIfError(proj.Name, err)
+			if report != nil {
+				prov := newProvenance(job, s.ArtifactFileName, report)
+				for _, res := range report.Results {
+					resultsList = append(resultsList, Result{Result: res, Provenance: prov})
 				}
-				projResult.ReportResult = resultsList
+				projResult.Reports = append(projResult.Reports, Report{
+					Provenance: prov,
+					Metadata:   report.Metadata,
+				})
 			}
+			projResult.ReportResult = resultsList
+		}
 
-			trivyIgnore, err := s.getTrivyIgnore(projResult.ProjId, proj.DefaultBranch)
-			logIfError(proj.Name, err)
-			if trivyIgnore != nil {
-				projResult.Ignore = trivyIgnore
-			}
+		trivyIgnore, err := s.getTrivyIgnore(projResult.ProjId, proj.DefaultBranch)
+		logIfError(proj.Name, err)
+		if trivyIgnore != nil {
+			projResult.Ignore = trivyIgnore
+		}
 
-			channel <- projResult
-		} else {
-			logger.WithField("Project", proj.Name).Debugln("Filtered out")
+		select {
+		case channel <- projResult:
+		case <-done:
+			return
 		}
 	}
-	wg.Done()
 }
 
-func (s Scan) processResults(projResults chan *trivy, resultsChannel chan TrivyResults) {
-	results := TrivyResults{}
+// processResults passes the results to the handler. On the first error of the
+// handler done is closed to cancel the scanners.
+func (s Scan) processResults(projResults chan *trivy, handler func(result TrivyResults) error, done chan struct{}, errChannel chan error) {
+	var err error
 	for scanResult := range projResults {
+		s.FindingFilter.apply(scanResult)
 		scanResult.check()
-		if scanResult.Ignore != nil || (scanResult.ReportResult != nil && scanResult.Vulnerabilities.Count > 0) {
-			results = append(results, scanResult)
+		if scanResult.Ignore != nil || (scanResult.ReportResult != nil && (scanResult.Vulnerabilities.Count > 0 || scanResult.Vulnerabilities.Licenses.Count > 0)) || scanResult.hasEOSL() {
+			if err = handler(TrivyResults{scanResult}); err != nil {
+				close(done)
+				break
+			}
 		}
 	}
-	resultsChannel <- results
-	close(resultsChannel)
+	errChannel <- err
+	close(errChannel)
 }
 
 func (s Scan) getTrivyJob(jobName string, projId int) ([]gitlab.Job, error) {
@@ -126,7 +207,7 @@ func (s Scan) getTrivyJob(jobName string, projId int) ([]gitlab.Job, error) {
 	return resultJobList, err
 }
 
-func (s Scan) getTrivyResult(fileName string, job gitlab.Job) (types.Results, error) {
+func (s Scan) getTrivyResult(fileName string, job gitlab.Job) (*types.Report, error) {
 
 	artifacts, response, err := s.GitLabClient.JobsClient.GetJobArtifacts(job.Project.ID, job.ID)
 	if err != nil {
@@ -143,7 +224,7 @@ func (s Scan) getTrivyResult(fileName string, job gitlab.Job) (types.Results, er
 	return s.reportFromFile(bt)
 }
 
-func (s Scan) reportFromFile(bt []byte) (types.Results, error) {
+func (s Scan) reportFromFile(bt []byte) (*types.Report, error) {
 	jsonReport := &types.Report{}
 	err := json.Unmarshal(bt, jsonReport)
 	if err != nil {
@@ -151,11 +232,13 @@ func (s Scan) reportFromFile(bt []byte) (types.Results, error) {
 		if err = json.Unmarshal(bt, jsonResult); err != nil {
 			return nil, err
 		} else {
-			return *jsonResult, nil
+			maskSecrets(*jsonResult)
+			return &types.Report{Results: *jsonResult}, nil
 		}
 	}
 
-	return jsonReport.Results, nil
+	maskSecrets(jsonReport.Results)
+	return jsonReport, nil
 }
 
 func (s Scan) getTrivyIgnore(projId int, branch string) ([]string, error) {
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/steffakasid/trivy-scanner/internal/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -87,6 +89,7 @@ func TestScanGroup(t *testing.T) {
 		assertProjNoIgnore(t, result, 44)
 		assertProjNoIgnore(t, result, 45)
 		assertProjNoIgnore(t, result, 46)
+		assertProvenance(t, result, jobName, jobID)
 	})
 
 	t.Run("success without filter", func(t *testing.T) {
@@ -110,6 +113,83 @@ func TestScanGroup(t *testing.T) {
 		assertProjNoIgnore(t, result, 45)
 		assertProjNoIgnore(t, result, 46)
 	})
+
+	streamScanner := func(projs []*gitlab.Project) *Scan {
+		mockGit := InitMock()
+		mockGetLatestPipeline(projs, mockGit.PipelinesClient.(*mocks.GitLabPipelines))
+		mockListPipelineJobsForProject(projs, 0, jobName, mockGit.JobsClient.(*mocks.GitLabJobs))
+		mockListProjectJobsForProject(projs, jobName, mockGit.JobsClient.(*mocks.GitLabJobs), 10, 15)
+		mockDownloadArtifactsFileForProjects(t, projs, jobID, mockGit.JobsClient.(*mocks.GitLabJobs), 25, 30)
+		mockGetRawFileForProjects(t, projs, branch, mockGit.RepositoryFiles.(*mocks.GitLabRepositoryFiles), 44, 45, 46)
+		scan, err := InitScanner(grpID, jobName, artifactFilename, "", mockGit)
+		assert.NoError(t, err)
+		return scan
+	}
+
+	t.Run("stream projects", func(t *testing.T) {
+		projs := generateProjects(50, branch)
+		calls := 0
+		err := streamScanner(projs).StreamProjects(projs, func(result TrivyResults) error {
+			calls++
+			assert.Len(t, result, 1)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 50, calls)
+	})
+
+	for _, count := range []int{1, 11} {
+		t.Run(fmt.Sprintf("scan %d projects", count), func(t *testing.T) {
+			projs := generateProjects(count, branch)
+			results, err := streamScanner(projs).ScanProjects(projs)
+			require.NoError(t, err)
+			ids := []int{}
+			for _, result := range results {
+				ids = append(ids, result.ProjId)
+			}
+			assert.ElementsMatch(t, projectIDs(projs), ids)
+		})
+	}
+
+	t.Run("project attributes", func(t *testing.T) {
+		projs := generateProjects(50, branch)
+		projs[1].Archived = true
+		projs[2].ForkedFromProject = &gitlab.ForkParent{ID: 99}
+		require.NoError(t, json.Unmarshal([]byte(`{"namespace": {"full_path": "platform"}, "shared_with_groups": [{"group_id": 7, "group_full_path": "security/tools"}]}`), projs[3]))
+		require.NoError(t, json.Unmarshal([]byte(`{"namespace": {"full_path": "platform/team"}, "shared_with_groups": [{"group_id": 8, "group_full_path": "platform/other"}]}`), projs[4]))
+		scan := streamScanner(projs)
+		scan.Sources = map[int]Target{
+			3: {Kind: TargetGroup, ID: "7", Label: "security", Path: "security"},
+			4: {Kind: TargetGroup, ID: "1234", Label: "platform", Path: "platform"},
+		}
+		results, err := scan.ScanProjects(projs)
+		require.NoError(t, err)
+		require.Len(t, results, 50)
+		for _, result := range results {
+			assert.Equal(t, result.ProjId == 1, result.Archived)
+			assert.Equal(t, result.ProjId == 2, result.Fork)
+			assert.Equal(t, result.ProjId == 3, result.Shared)
+			assert.Equal(t, scan.Sources[result.ProjId].Label, result.Label)
+		}
+	})
+
+	t.Run("stream projects handler error", func(t *testing.T) {
+		projs := generateProjects(50, branch)
+		scan := streamScanner(projs)
+		calls := 0
+		err := scan.StreamProjects(projs, func(result TrivyResults) error {
+			calls++
+			if calls == 3 {
+				return errors.New("write failed")
+			}
+			return nil
+		})
+		assert.EqualError(t, err, "write failed")
+		assert.Equal(t, 3, calls)
+		// every scanner finishes at most the project it's scanning
+		scanned := len(scan.GitLabClient.PipelinesClient.(*mocks.GitLabPipelines).Calls)
+		assert.LessOrEqual(t, scanned, calls+len(projs)/chunkSize)
+	})
 }
 
 func TestGetTrivyResult(t *testing.T) {
@@ -125,23 +205,57 @@ func TestGetTrivyResult(t *testing.T) {
 		mockDownloadArtifactsFile(t, projID, jobID, 1, scan.GitLabClient.JobsClient.(*mocks.GitLabJobs))
 
 		job := gitlab.Job{ID: 123, Project: &gitlab.Project{ID: 1123}}
-		results, err := scan.getTrivyResult(scan.ArtifactFileName, job)
+		report, err := scan.getTrivyResult(scan.ArtifactFileName, job)
 		assert.NoError(t, err)
-		assert.Len(t, results, 6)
+		assert.Len(t, report.Results, 6)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		mockDownloadArtifactsFile(t, projID, jobID, 1, scan.GitLabClient.JobsClient.(*mocks.GitLabJobs), 1)
 
 		job := gitlab.Job{ID: 123, Project: &gitlab.Project{ID: 1123}}
-		results, err := scan.getTrivyResult(scan.ArtifactFileName, job)
+		report, err := scan.getTrivyResult(scan.ArtifactFileName, job)
 		assert.Error(t, err)
 		assert.EqualError(t, err, "Fail")
-		assert.Nil(t, results)
+		assert.Nil(t, report)
 	})
 
 }
 
+func TestReportFromFile(t *testing.T) {
+	scan := Scan{}
+
+	t.Run("report with metadata", func(t *testing.T) {
+		bt := []byte(`{
+			"SchemaVersion": 2,
+			"ArtifactName": "registry.example.com/app:1.0",
+			"ArtifactType": "container_image",
+			"Metadata": {
+				"OS": {"Family": "alpine", "Name": "3.7.1", "EOSL": true},
+				"ImageID": "sha256:1234",
+				"RepoDigests": ["registry.example.com/app@sha256:abcd"]
+			},
+			"Results": [{"Target": "app (alpine 3.7.1)"}]
+		}`)
+		report, err := scan.reportFromFile(bt)
+		assert.NoError(t, err)
+		assert.Equal(t, "registry.example.com/app:1.0", report.ArtifactName)
+		assert.Equal(t, "alpine", string(report.Metadata.OS.Family))
+		assert.True(t, report.Metadata.OS.Eosl)
+		assert.Equal(t, []string{"registry.example.com/app@sha256:abcd"}, report.Metadata.RepoDigests)
+		assert.Len(t, report.Results, 1)
+	})
+
+	t.Run("plain results list", func(t *testing.T) {
+		bt, err := os.ReadFile("../test/trivy-result.json")
+		assert.NoError(t, err)
+		report, err := scan.reportFromFile(bt)
+		assert.NoError(t, err)
+		assert.Nil(t, report.Metadata.OS)
+		assert.Len(t, report.Results, 6)
+	})
+}
+
 func TestGetTrivyIgnore(t *testing.T) {
 	branch := "main"
 	projId := 1123
@@ -179,6 +293,16 @@ func assertProjNoResult(t *testing.T, result TrivyResults, id int) {
 	}
 }
 
+func assertProvenance(t *testing.T, result TrivyResults, jobName string, jobID int) {
+	for _, res := range result {
+		for _, tgt := range res.ReportResult {
+			assert.NotNil(t, tgt.Provenance)
+			assert.Equal(t, jobName, tgt.Provenance.JobName)
+			assert.Equal(t, jobID, tgt.Provenance.JobID)
+		}
+	}
+}
+
 func assertProjNoIgnore(t *testing.T, result TrivyResults, id int) {
 	for _, res := range result {
 		if res.ProjId == id {
@@ -201,6 +325,14 @@ func generateProjects(number int, branch string) []*gitlab.Project {
 	return projs
 }
 
+func projectIDs(projs []*gitlab.Project) []int {
+	ids := []int{}
+	for _, proj := range projs {
+		ids = append(ids, proj.ID)
+	}
+	return ids
+}
+
 func mockGetLatestPipeline(projs []*gitlab.Project, mock *mocks.GitLabPipelines, errProj ...int) {
 	for _, proj := range projs {
 		if isErrorCall(errProj, proj.ID) {
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+const secretMask = "********"
+
+// Secret is a single secret finding together with the target (file) it was
+// found in.
+type Secret struct {
+	Target     string
+	Provenance *Provenance `json:",omitempty"`
+	types.DetectedSecret
+}
+
+// Location returns the file and line(s) the secret was found at.
+func (s Secret) Location() string {
+	if s.EndLine > s.StartLine {
+		return fmt.Sprintf("%s:%d-%d", s.Target, s.StartLine, s.EndLine)
+	}
+	return fmt.Sprintf("%s:%d", s.Target, s.StartLine)
+}
+
+// maskSecrets masks the matched content of all secret findings. Trivy already
+// masks the secret itself, but we never want to rely on that so everything
+// after the key name is replaced and the code snippet is dropped.
+func maskSecrets(results types.Results) {
+	for i := range results {
+		for j := range results[i].Secrets {
+			results[i].Secrets[j].Match = maskSecret(results[i].Secrets[j].Match)
+			results[i].Secrets[j].Code = ftypes.Code{}
+		}
+	}
+}
+
+func maskSecret(match string) string {
+	if idx := strings.IndexAny(match, "=:"); idx >= 0 {
+		return strings.TrimSpace(match[:idx+1]) + secretMask
+	}
+	return secretMask
+}
+
+func (r *trivy) collectSecrets() []Secret {
+	secrets := []Secret{}
+	for _, tgt := range r.ReportResult {
+		for _, s := range tgt.Secrets {
+			secrets = append(secrets, Secret{Target: tgt.Target, Provenance: tgt.Provenance, DetectedSecret: s})
+		}
+	}
+	return secrets
+}
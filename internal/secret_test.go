@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY=********", maskSecret("AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG"))
+	assert.Equal(t, "password:********", maskSecret("password: hunter2"))
+	assert.Equal(t, "********", maskSecret("ghp_1234567890abcdef"))
+	assert.Equal(t, "********", maskSecret(""))
+}
+
+func TestMaskSecrets(t *testing.T) {
+	results := types.Results{
+		{
+			Target: "config/.env",
+			Secrets: []types.DetectedSecret{
+				{
+					RuleID: "aws-secret-access-key",
+					Match:  "AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG",
+					Code: ftypes.Code{Lines: []ftypes.Line{
+						{Number: 1, Content: "AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG", IsCause: true},
+					}},
+				},
+			},
+		},
+	}
+	maskSecrets(results)
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY=********", results[0].Secrets[0].Match)
+	assert.Empty(t, results[0].Secrets[0].Code.Lines)
+}
+
+func TestCollectSecrets(t *testing.T) {
+	prov := &Provenance{JobID: 1}
+	result := &trivy{
+		ReportResult: Results{
+			{Result: types.Result{Target: "app/Dockerfile"}, Provenance: prov},
+			{
+				Result: types.Result{
+					Target: "app/.env",
+					Secrets: []types.DetectedSecret{
+						{RuleID: "github-pat", Severity: "CRITICAL", StartLine: 3, EndLine: 3},
+						{RuleID: "private-key", Severity: "HIGH", StartLine: 10, EndLine: 12},
+					},
+				},
+				Provenance: prov,
+			},
+		},
+	}
+	result.check()
+	assert.Equal(t, 2, result.Vulnerabilities.Secrets)
+	assert.Equal(t, 2, result.Vulnerabilities.Count)
+	assert.Len(t, result.Secrets, 2)
+	assert.Equal(t, "app/.env:3", result.Secrets[0].Location())
+	assert.Equal(t, "app/.env:10-12", result.Secrets[1].Location())
+	assert.Equal(t, "private-key", result.Secrets[1].RuleID)
+	assert.Same(t, prov, result.Secrets[1].Provenance)
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"sort"
+)
+
+// Summary holds the aggregated numbers of all results, e.g. to render a
+// dashboard.
+type Summary struct {
+	Projects           int
+	Critical           int
+	High               int
+	Medium             int
+	Low                int
+	Unknown            int
+	Secrets            int
+	Misconfigurations  int
+	Licenses           int
+	TopProjects        []ProjectSummary
+	TopVulnerabilities []VulnerabilitySummary
+}
+
+// ProjectSummary holds the number of vulnerabilities per severity of one
+// project.
+type ProjectSummary struct {
+	Name     string
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Unknown  int
+	Total    int
+}
+
+// VulnerabilitySummary holds the number of projects affected by one
+// vulnerability.
+type VulnerabilitySummary struct {
+	ID       string
+	Severity string
+	Title    string
+	Projects int
+}
+
+// Summarize aggregates all results. TopProjects and TopVulnerabilities are
+// limited to top entries.
+func (t TrivyResults) Summarize(top int) Summary {
+	summary := Summary{Projects: len(t)}
+	vullies := map[string]*VulnerabilitySummary{}
+	for _, result := range t {
+		proj := ProjectSummary{Name: result.ProjName}
+		seen := map[string]bool{}
+		for _, tgt := range result.ReportResult {
+			crit, hi, med, lo, un := GetSummary(tgt.Vulnerabilities)
+			proj.Critical += crit
+			proj.High += hi
+			proj.Medium += med
+			proj.Low += lo
+			proj.Unknown += un
+			for _, v := range tgt.Vulnerabilities {
+				if seen[v.VulnerabilityID] {
+					continue
+				}
+				seen[v.VulnerabilityID] = true
+				vs, ok := vullies[v.VulnerabilityID]
+				if !ok {
+					vs = &VulnerabilitySummary{ID: v.VulnerabilityID, Severity: v.Severity, Title: v.Title}
+					vullies[v.VulnerabilityID] = vs
+				}
+				vs.Projects++
+			}
+		}
+		proj.Total = proj.Critical + proj.High + proj.Medium + proj.Low + proj.Unknown
+		summary.Critical += proj.Critical
+		summary.High += proj.High
+		summary.Medium += proj.Medium
+		summary.Low += proj.Low
+		summary.Unknown += proj.Unknown
+		summary.Secrets += result.Vulnerabilities.Secrets
+		summary.Misconfigurations += result.Vulnerabilities.Misconfigurations.Fail
+		summary.Licenses += result.Vulnerabilities.Licenses.Count
+		summary.TopProjects = append(summary.TopProjects, proj)
+	}
+
+	sort.SliceStable(summary.TopProjects, func(i, j int) bool {
+		a, b := summary.TopProjects[i], summary.TopProjects[j]
+		if a.Critical != b.Critical {
+			return a.Critical > b.Critical
+		}
+		if a.High != b.High {
+			return a.High > b.High
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Name < b.Name
+	})
+	if len(summary.TopProjects) > top {
+		summary.TopProjects = summary.TopProjects[:top]
+	}
+
+	for _, vs := range vullies {
+		summary.TopVulnerabilities = append(summary.TopVulnerabilities, *vs)
+	}
+	sort.Slice(summary.TopVulnerabilities, func(i, j int) bool {
+		a, b := summary.TopVulnerabilities[i], summary.TopVulnerabilities[j]
+		if a.Projects != b.Projects {
+			return a.Projects > b.Projects
+		}
+		if SeverityRank(a.Severity) != SeverityRank(b.Severity) {
+			return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+		}
+		return a.ID < b.ID
+	})
+	if len(summary.TopVulnerabilities) > top {
+		summary.TopVulnerabilities = summary.TopVulnerabilities[:top]
+	}
+	return summary
+}
+
+// SeverityRank orders the trivy severities from UNKNOWN (0) to CRITICAL (4).
+func SeverityRank(severity string) int {
+	switch severity {
+	case "CRITICAL":
+		return 4
+	case "HIGH":
+		return 3
+	case "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"testing"
+
+	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	vulli := func(id, severity string) types.DetectedVulnerability {
+		return types.DetectedVulnerability{VulnerabilityID: id, Vulnerability: dbtypes.Vulnerability{Severity: severity}}
+	}
+	trivyResults := TrivyResults{
+		&trivy{ProjName: "proj1", ReportResult: Results{
+			{Result: types.Result{Vulnerabilities: []types.DetectedVulnerability{vulli("CVE-1", "HIGH"), vulli("CVE-2", "LOW")}}},
+			{Result: types.Result{Vulnerabilities: []types.DetectedVulnerability{vulli("CVE-1", "HIGH")}}},
+		}},
+		&trivy{ProjName: "proj2", ReportResult: Results{
+			{Result: types.Result{
+				Vulnerabilities: []types.DetectedVulnerability{vulli("CVE-1", "HIGH"), vulli("CVE-3", "CRITICAL")},
+				Secrets:         []types.DetectedSecret{{RuleID: "github-pat"}},
+			}},
+		}},
+		&trivy{ProjName: "proj3"},
+	}
+	trivyResults.Check()
+
+	summary := trivyResults.Summarize(2)
+	assert.Equal(t, 3, summary.Projects)
+	assert.Equal(t, 1, summary.Critical)
+	assert.Equal(t, 3, summary.High)
+	assert.Equal(t, 1, summary.Low)
+	assert.Equal(t, 1, summary.Secrets)
+	assert.Len(t, summary.TopProjects, 2)
+	assert.Equal(t, "proj2", summary.TopProjects[0].Name)
+	assert.Equal(t, 2, summary.TopProjects[0].Total)
+	assert.Equal(t, "proj1", summary.TopProjects[1].Name)
+	assert.Equal(t, 3, summary.TopProjects[1].Total)
+	assert.Len(t, summary.TopVulnerabilities, 2)
+	assert.Equal(t, VulnerabilitySummary{ID: "CVE-1", Severity: "HIGH", Projects: 2}, summary.TopVulnerabilities[0])
+	assert.Equal(t, "CVE-3", summary.TopVulnerabilities[1].ID)
+}
+
+func TestSeverityRank(t *testing.T) {
+	assert.Equal(t, 4, SeverityRank("CRITICAL"))
+	assert.Equal(t, 1, SeverityRank("LOW"))
+	assert.Equal(t, 0, SeverityRank("UNKNOWN"))
+	assert.Equal(t, 0, SeverityRank("other"))
+}
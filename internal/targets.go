@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	TargetGroup   = "group"
+	TargetProject = "project"
+)
+
+// Target is a group or a single project which is scanned. ID is the numeric
+// ID or the full path. The label is added to all projects of the target and
+// defaults to the ID. Path is the full path of a group, it's resolved when
+// the projects are listed.
+type Target struct {
+	Kind  string
+	ID    string
+	Label string
+	Path  string
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s %s", t.Kind, t.ID)
+}
+
+// ParseTargets parses the groups and projects given as id-or-path[=label] and
+// the targets file. Every line of the file is either "group id-or-path[=label]"
+// or "project id-or-path[=label]", empty lines and lines starting with # are
+// ignored. Targets which are given more than once are only returned once.
+func ParseTargets(groups, projects []string, file string) ([]Target, error) {
+	targets := []Target{}
+	seen := map[string]bool{}
+	add := func(kind, definition string) {
+		id, label, _ := strings.Cut(strings.TrimSpace(definition), "=")
+		target := Target{Kind: kind, ID: strings.Trim(strings.TrimSpace(id), "/"), Label: strings.TrimSpace(label)}
+		if target.ID == "" {
+			return
+		}
+		if target.Label == "" {
+			target.Label = target.ID
+		}
+		if seen[target.String()] {
+			logger.Debugf("Skip duplicate %s", target)
+			return
+		}
+		seen[target.String()] = true
+		targets = append(targets, target)
+	}
+
+	for _, group := range groups {
+		add(TargetGroup, group)
+	}
+	for _, project := range projects {
+		add(TargetProject, project)
+	}
+	if file == "" {
+		return targets, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		kind, definition, _ := strings.Cut(text, " ")
+		kind = strings.ToLower(kind)
+		if (kind != TargetGroup && kind != TargetProject) || strings.TrimSpace(definition) == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"group id-or-path[=label]\" or \"project id-or-path[=label]\"", file, line)
+		}
+		add(kind, definition)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+	return targets, nil
+}
+
+// shares returns true if the project was listed by the group target because
+// it's shared with the group or one of its subgroups, i.e. it doesn't belong
+// to the group or one of its subgroups.
+func (t Target) shares(proj *gitlab.Project) bool {
+	if t.Kind != TargetGroup || t.Path == "" || proj.Namespace == nil {
+		return false
+	}
+	namespace := proj.Namespace.FullPath
+	return namespace != t.Path && !strings.HasPrefix(namespace, t.Path+"/")
+}
+
+// GetTargetProjects lists the projects of all targets and returns the target
+// of every project. Projects which belong to more than one target are only
+// returned once with the first target.
+func (c GitLabClient) GetTargetProjects(targets []Target) ([]*gitlab.Project, map[int]Target, error) {
+	allProjs := []*gitlab.Project{}
+	sources := map[int]Target{}
+	for _, target := range targets {
+		var projs []*gitlab.Project
+		switch target.Kind {
+		case TargetGroup:
+			group, _, err := c.GroupsClient.GetGroup(target.ID, &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get %s: %w", target, err)
+			}
+			target.Path = group.FullPath
+			projs, err = c.GetAllGroupProjects(target.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list projects of %s: %w", target, err)
+			}
+		case TargetProject:
+			proj, _, err := c.ProjectsClient.GetProject(target.ID, &gitlab.GetProjectOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get %s: %w", target, err)
+			}
+			projs = []*gitlab.Project{proj}
+		default:
+			return nil, nil, fmt.Errorf("unknown target kind %q", target.Kind)
+		}
+
+		for _, proj := range projs {
+			if _, ok := sources[proj.ID]; ok {
+				logger.WithField("Project", proj.PathWithNamespace).Debugf("Skip duplicate of %s", target)
+				continue
+			}
+			sources[proj.ID] = target
+			allProjs = append(allProjs, proj)
+		}
+	}
+	return allProjs, sources, nil
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseTargets(t *testing.T) {
+	t.Run("flags", func(t *testing.T) {
+		targets, err := ParseTargets([]string{"1234", "my/group/=platform", " 1234 "}, []string{"my/app", ""}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []Target{
+			{Kind: TargetGroup, ID: "1234", Label: "1234"},
+			{Kind: TargetGroup, ID: "my/group", Label: "platform"},
+			{Kind: TargetProject, ID: "my/app", Label: "my/app"},
+		}, targets)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "targets")
+		require.NoError(t, os.WriteFile(file, []byte("# security owned\ngroup 1234\n\nproject 42=standalone\nGROUP 99=legacy\n"), 0o600))
+		targets, err := ParseTargets([]string{"1234=first"}, nil, file)
+		require.NoError(t, err)
+		assert.Equal(t, []Target{
+			{Kind: TargetGroup, ID: "1234", Label: "first"},
+			{Kind: TargetProject, ID: "42", Label: "standalone"},
+			{Kind: TargetGroup, ID: "99", Label: "legacy"},
+		}, targets)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "targets")
+		require.NoError(t, os.WriteFile(file, []byte("group 1234\nuser me\n"), 0o600))
+		_, err := ParseTargets(nil, nil, file)
+		assert.EqualError(t, err, file+`:2: expected "group id-or-path[=label]" or "project id-or-path[=label]"`)
+
+		_, err = ParseTargets(nil, nil, filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorContains(t, err, "failed to read targets file")
+	})
+}
+
+func TestGetTargetProjects(t *testing.T) {
+	t.Run("deduplicate projects", func(t *testing.T) {
+		gitLabClient := InitMock()
+		mockGetGroup(gitLabClient.GroupsClient.(*mocks.GitLabGroups), "1234", "platform")
+		groupProjs := mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 1, "1234", defaultGroupOptions())
+		gitLabClient.ProjectsClient.(*mocks.GitLabProjects).EXPECT().GetProject("my/app", &gitlab.GetProjectOptions{}).Return(&gitlab.Project{ID: 11}, &gitlab.Response{}, nil).Once()
+		app := &gitlab.Project{ID: 42}
+		gitLabClient.ProjectsClient.(*mocks.GitLabProjects).EXPECT().GetProject("42", &gitlab.GetProjectOptions{}).Return(app, &gitlab.Response{}, nil).Once()
+
+		group := Target{Kind: TargetGroup, ID: "1234", Label: "platform"}
+		resolved := Target{Kind: TargetGroup, ID: "1234", Label: "platform", Path: "platform"}
+		standalone := Target{Kind: TargetProject, ID: "42", Label: "standalone"}
+		projs, sources, err := gitLabClient.GetTargetProjects([]Target{
+			group,
+			{Kind: TargetProject, ID: "my/app", Label: "my/app"},
+			standalone,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, append(groupProjs, app), projs)
+		assert.Equal(t, map[int]Target{10: resolved, 11: resolved, 42: standalone}, sources)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		gitLabClient := InitMock()
+		gitLabClient.ProjectsClient.(*mocks.GitLabProjects).EXPECT().GetProject("my/app", &gitlab.GetProjectOptions{}).Return(nil, nil, errors.New("404 Not Found")).Once()
+
+		projs, sources, err := gitLabClient.GetTargetProjects([]Target{{Kind: TargetProject, ID: "my/app", Label: "my/app"}})
+		assert.Nil(t, projs)
+		assert.Nil(t, sources)
+		assert.EqualError(t, err, "failed to get project my/app: 404 Not Found")
+	})
+
+	t.Run("group error", func(t *testing.T) {
+		gitLabClient := InitMock()
+		gitLabClient.GroupsClient.(*mocks.GitLabGroups).EXPECT().GetGroup("1234", &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)}).Return(nil, nil, errors.New("404 Not Found")).Once()
+
+		_, _, err := gitLabClient.GetTargetProjects([]Target{{Kind: TargetGroup, ID: "1234", Label: "1234"}})
+		assert.EqualError(t, err, "failed to get group 1234: 404 Not Found")
+	})
+
+	t.Run("labels are remembered", func(t *testing.T) {
+		gitLabClient := InitMock()
+		mockGetGroup(gitLabClient.GroupsClient.(*mocks.GitLabGroups), "1234", "platform")
+		mockListGroupProjects(gitLabClient.GroupsClient.(*mocks.GitLabGroups), 1, "1234", defaultGroupOptions())
+		group := Target{Kind: TargetGroup, ID: "1234", Label: "platform"}
+		s := &Scan{GitLabClient: gitLabClient, Targets: []Target{group}}
+
+		projs, err := s.ListProjects()
+		require.NoError(t, err)
+		assert.Len(t, projs, 2)
+		group.Path = "platform"
+		assert.Equal(t, map[int]Target{10: group, 11: group}, s.Sources)
+	})
+}
+
+func TestTargetShares(t *testing.T) {
+	project := func(namespace string, sharedWith ...string) *gitlab.Project {
+		proj := &gitlab.Project{}
+		groups := []string{}
+		for _, group := range sharedWith {
+			groups = append(groups, fmt.Sprintf(`{"group_full_path": %q}`, group))
+		}
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"namespace": {"full_path": %q}, "shared_with_groups": [%s]}`, namespace, strings.Join(groups, ","))), proj))
+		return proj
+	}
+	numeric := Target{Kind: TargetGroup, ID: "1234", Label: "1234", Path: "platform"}
+
+	tests := []struct {
+		name   string
+		target Target
+		proj   *gitlab.Project
+		shared bool
+	}{
+		{name: "own project", target: numeric, proj: project("platform"), shared: false},
+		{name: "subgroup project", target: numeric, proj: project("platform/team/backend"), shared: false},
+		{name: "own project shared with own subgroup", target: numeric, proj: project("platform/team", "platform/other"), shared: false},
+		{name: "shared with group", target: numeric, proj: project("security", "platform"), shared: true},
+		{name: "shared with subgroup", target: numeric, proj: project("security/tools", "platform/team"), shared: true},
+		{name: "namespace with same prefix", target: numeric, proj: project("platform-legacy"), shared: true},
+		{name: "path target", target: Target{Kind: TargetGroup, ID: "platform/team", Path: "platform/team"}, proj: project("platform/team/app"), shared: false},
+		{name: "unresolved group", target: Target{Kind: TargetGroup, ID: "1234"}, proj: project("security"), shared: false},
+		{name: "project target", target: Target{Kind: TargetProject, ID: "security/tools"}, proj: project("security"), shared: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.shared, tt.target.shares(tt.proj))
+		})
+	}
+}
+
+func mockGetGroup(mock *mocks.GitLabGroups, gid, fullPath string) {
+	mock.EXPECT().GetGroup(gid, &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)}).Return(&gitlab.Group{FullPath: fullPath}, &gitlab.Response{}, nil).Once()
+}
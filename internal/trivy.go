@@ -1,21 +1,67 @@
 package internal
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/xanzy/go-gitlab"
 )
 
 type trivy struct {
 	ProjId          int
 	ProjName        string
+	ProjNamespace   string
+	Instance        string `json:",omitempty"`
+	Label           string `json:",omitempty"`
+	Archived        bool   `json:",omitempty"`
+	Fork            bool   `json:",omitempty"`
+	Shared          bool   `json:",omitempty"`
+	Group           string `json:",omitempty"`
 	Vulnerabilities vulnerabilities
 	Ignore          []string
-	ReportResult    types.Results
+	ReportResult    Results
+	Reports         []Report
+	Secrets         []Secret
+	Licenses        []License
+}
+
+// Report keeps the artifact information of a single trivy report which was
+// found for a project. A project has one Report per matching job.
+type Report struct {
+	Provenance *Provenance
+	Metadata   types.Metadata
+}
+
+// Provenance records where a trivy report came from.
+type Provenance struct {
+	JobName      string
+	JobID        int
+	PipelineID   int
+	Ref          string
+	CommitSHA    string
+	ArtifactFile string
+	ArtifactName string    `json:",omitempty"`
+	CreatedAt    time.Time `json:",omitzero"`
+}
+
+// Result is a single trivy result (target) together with the provenance of
+// the report it was taken from.
+type Result struct {
+	types.Result
+	Provenance *Provenance `json:",omitempty"`
 }
 
+type Results []Result
+
 type vulnerabilities struct {
-	Count    int
-	High     int
-	Critical int
+	Count             int
+	High              int
+	Critical          int
+	Secrets           int
+	Misconfigurations misconfigurations
+	Licenses          licenses
 }
 
 type TrivyResults []*trivy
@@ -32,6 +78,11 @@ func (r *trivy) check() {
 		vullies.Count += len(pkgResult.Misconfigurations)
 		vullies.Count += len(pkgResult.Vulnerabilities)
 		vullies.Count += len(pkgResult.Secrets)
+		vullies.Secrets += len(pkgResult.Secrets)
+		pass, fail, exception := GetMisconfSummary(pkgResult.Misconfigurations)
+		vullies.Misconfigurations.Pass += pass
+		vullies.Misconfigurations.Fail += fail
+		vullies.Misconfigurations.Exception += exception
 		for _, v := range pkgResult.Vulnerabilities {
 			if v.Severity == "CRITICAL" {
 				vullies.Critical++
@@ -40,9 +91,72 @@ func (r *trivy) check() {
 			}
 		}
 	}
+	r.Secrets = r.collectSecrets()
+	r.Licenses, vullies.Licenses = r.collectLicenses()
 	r.Vulnerabilities = vullies
 }
 
+func newProvenance(job gitlab.Job, artifactFile string, report *types.Report) *Provenance {
+	prov := &Provenance{
+		JobName:      job.Name,
+		JobID:        job.ID,
+		PipelineID:   job.Pipeline.ID,
+		Ref:          job.Ref,
+		CommitSHA:    job.Pipeline.Sha,
+		ArtifactFile: artifactFile,
+		ArtifactName: report.ArtifactName,
+		CreatedAt:    report.CreatedAt,
+	}
+	if prov.CommitSHA == "" && job.Commit != nil {
+		prov.CommitSHA = job.Commit.ID
+	}
+	if prov.CreatedAt.IsZero() && job.FinishedAt != nil {
+		prov.CreatedAt = *job.FinishedAt
+	}
+	return prov
+}
+
+// String returns a short one line description of the provenance which can be
+// used by all printers.
+func (p *Provenance) String() string {
+	if p == nil {
+		return ""
+	}
+	parts := []string{
+		fmt.Sprintf("job %s (#%d)", p.JobName, p.JobID),
+		fmt.Sprintf("pipeline #%d", p.PipelineID),
+		fmt.Sprintf("ref %s@%s", p.Ref, shortSHA(p.CommitSHA)),
+	}
+	if p.ArtifactName != "" {
+		parts = append(parts, fmt.Sprintf("artifact %s", p.ArtifactName))
+	}
+	if !p.CreatedAt.IsZero() {
+		parts = append(parts, fmt.Sprintf("scanned %s", p.CreatedAt.Format(time.RFC3339)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// CVSSScore returns the highest CVSS score of all vendors. CVSS v4.0 and v3
+// scores are preferred over v2 scores.
+func CVSSScore(v types.DetectedVulnerability) float64 {
+	score, v2Score := 0.0, 0.0
+	for _, cvss := range v.CVSS {
+		score = max(score, cvss.V40Score, cvss.V3Score)
+		v2Score = max(v2Score, cvss.V2Score)
+	}
+	if score == 0 {
+		return v2Score
+	}
+	return score
+}
+
 func GetSummary(dv []types.DetectedVulnerability) (critical, high, medium, low, unkown int) {
 	for _, v := range dv {
 		if v.Severity == "CRITICAL" {
@@ -2,18 +2,20 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	dbtypes "github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
 )
 
 func TestCheck(t *testing.T) {
 	trivyResults := &TrivyResults{
 		&trivy{
 			ProjId: 1,
-			ReportResult: types.Results{
-				types.Result{
+			ReportResult: Results{
+				{Result: types.Result{
 					Vulnerabilities: []types.DetectedVulnerability{
 						{
 							Vulnerability: dbtypes.Vulnerability{Severity: "CRITICAL"},
@@ -22,13 +24,13 @@ func TestCheck(t *testing.T) {
 							Vulnerability: dbtypes.Vulnerability{Severity: "HIGH"},
 						},
 					},
-				},
+				}},
 			},
 		},
 		&trivy{
 			ProjId: 2,
-			ReportResult: types.Results{
-				types.Result{
+			ReportResult: Results{
+				{Result: types.Result{
 					Vulnerabilities: []types.DetectedVulnerability{
 						{
 							Vulnerability: dbtypes.Vulnerability{Severity: "LOW"},
@@ -37,7 +39,7 @@ func TestCheck(t *testing.T) {
 							Vulnerability: dbtypes.Vulnerability{Severity: "HIGH"},
 						},
 					},
-				},
+				}},
 			},
 		},
 	}
@@ -75,3 +77,30 @@ func TestSummary(t *testing.T) {
 	assert.Equal(t, 1, low)
 	assert.Equal(t, 1, other)
 }
+
+func TestProvenance(t *testing.T) {
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	finished := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	job := gitlab.Job{ID: 123, Name: "scan_oci_image_trivy", Ref: "main", FinishedAt: &finished}
+	job.Pipeline.ID = 456
+	job.Pipeline.Sha = "0123456789abcdef"
+
+	t.Run("from report", func(t *testing.T) {
+		prov := newProvenance(job, "trivy-results.json", &types.Report{ArtifactName: "registry/app:1.0", CreatedAt: created})
+		assert.Equal(t, "scan_oci_image_trivy", prov.JobName)
+		assert.Equal(t, 123, prov.JobID)
+		assert.Equal(t, 456, prov.PipelineID)
+		assert.Equal(t, "main", prov.Ref)
+		assert.Equal(t, "0123456789abcdef", prov.CommitSHA)
+		assert.Equal(t, "trivy-results.json", prov.ArtifactFile)
+		assert.Equal(t, "registry/app:1.0", prov.ArtifactName)
+		assert.Equal(t, created, prov.CreatedAt)
+		assert.Equal(t, "job scan_oci_image_trivy (#123), pipeline #456, ref main@01234567, artifact registry/app:1.0, scanned 2024-01-02T15:04:05Z", prov.String())
+	})
+
+	t.Run("fallback to job", func(t *testing.T) {
+		prov := newProvenance(job, "trivy-results.json", &types.Report{})
+		assert.Equal(t, finished, prov.CreatedAt)
+		assert.Equal(t, "job scan_oci_image_trivy (#123), pipeline #456, ref main@01234567, scanned 2024-01-03T00:00:00Z", prov.String())
+	})
+}
@@ -17,14 +17,17 @@ const (
 )
 
 const (
-	GITLAB_HOST     = "GITLAB_HOST"
-	GTILAB_TOKEN    = "GITLAB_TOKEN"
-	GITLAB_GROUP_ID = "GITLAB_GROUP_ID"
-	LOG_LEVEL       = "LOG_LEVEL"
-	METRICS_PORT    = "METRICS_PORT"
-	METRICS_CRON    = "METRICS_CRON"
-	ARTIFACT        = "ARTIFACT"
-	JOB_NAME        = "JOB_NAME"
+	GITLAB_HOST       = "GITLAB_HOST"
+	GTILAB_TOKEN      = "GITLAB_TOKEN"
+	GITLAB_TOKEN_TYPE = "GITLAB_TOKEN_TYPE"
+	GITLAB_TOKEN_FILE = "GITLAB_TOKEN_FILE"
+	CI_JOB_TOKEN      = "CI_JOB_TOKEN"
+	GITLAB_GROUP_ID   = "GITLAB_GROUP_ID"
+	LOG_LEVEL         = "LOG_LEVEL"
+	METRICS_PORT      = "METRICS_PORT"
+	METRICS_CRON      = "METRICS_CRON"
+	ARTIFACT          = "ARTIFACT"
+	JOB_NAME          = "JOB_NAME"
 )
 
 func init() {
@@ -38,42 +41,110 @@ func init() {
 	viper.SetDefault(JOB_NAME, "scan_oci_image_trivy")
 	viper.SetDefault(METRICS_PORT, 2112)
 	viper.BindEnv(GITLAB_GROUP_ID)
+	viper.BindEnv(GITLAB_TOKEN_TYPE)
+	viper.BindEnv(GITLAB_TOKEN_FILE)
+	viper.BindEnv(CI_JOB_TOKEN)
 	viper.SetDefault(METRICS_CRON, "@every 1h")
 }
 
-func InitConfig() {
+// InitConfig reads the config file and enables the env variables. It returns
+// an error if the config file can't be decrypted or parsed, the env variables
+// are used anyway.
+func InitConfig() error {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		logger.Fatal(err)
 	}
 
-	viper.AddConfigPath(home)
 	viper.SetConfigType(configFileType)
-	viper.SetConfigName(configFileName)
 
 	usedConfigFile := getConfigFilename(home)
 	if usedConfigFile != "" {
-		cleartext, err := decrypt.File(usedConfigFile, configFileType)
-
-		if err != nil {
-			logger.Warnf("Error decrypting. %s. Maybe you're not using an encrypted config?", err)
-			if err := viper.ReadInConfig(); err != nil {
-				logger.Warnf("Error reading config. %s. Are you using a config?", err)
-			} else {
-				logger.Debug("Using config file:", viper.ConfigFileUsed())
-			}
-		} else {
-			if err := viper.ReadConfig(bytes.NewBuffer(cleartext)); err != nil {
-				logger.Fatal(err)
-			} else {
-				logger.Debug("Using sops encrypted config file:", viper.ConfigFileUsed())
-			}
-		}
+		err = readConfig(usedConfigFile)
 	} else {
 		logger.Debug("No config file used!")
 	}
 	viper.AutomaticEnv()
 	SetLogLevel()
+	return err
+}
+
+func readConfig(file string) error {
+	config, err := ReadConfigFile(file)
+	if err != nil {
+		return err
+	}
+	if err := viper.ReadConfig(bytes.NewBuffer(config.cleartext)); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	if config.Encrypted {
+		logger.Debug("Using sops encrypted config file:", file)
+	} else {
+		logger.Debug("Using config file:", file)
+	}
+	return nil
+}
+
+// ConfigFile is a decrypted config file with its top level settings.
+type ConfigFile struct {
+	Path      string
+	Encrypted bool
+	Settings  map[string]interface{}
+	cleartext []byte
+}
+
+// ReadConfigFile reads and parses the config file. It's decrypted if it
+// contains sops metadata.
+func ReadConfigFile(file string) (ConfigFile, error) {
+	config := ConfigFile{Path: file}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return config, fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	settings, err := parseConfig(content)
+	if err != nil {
+		return config, fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	if _, ok := settings["sops"]; ok {
+		config.Encrypted = true
+		if content, err = decrypt.Data(content, configFileType); err != nil {
+			return config, fmt.Errorf("failed to decrypt config file %s: %w", file, err)
+		}
+		if settings, err = parseConfig(content); err != nil {
+			return config, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+	}
+	config.Settings = settings
+	config.cleartext = content
+	return config, nil
+}
+
+func parseConfig(content []byte) (map[string]interface{}, error) {
+	parsed := viper.New()
+	parsed.SetConfigType(configFileType)
+	if err := parsed.ReadConfig(bytes.NewBuffer(content)); err != nil {
+		return nil, err
+	}
+	return parsed.AllSettings(), nil
+}
+
+// ConfigFilePath returns the used config file or an empty string if there is
+// none.
+func ConfigFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return getConfigFilename(home)
+}
+
+// DefaultConfigFilePath returns the path of a new config file.
+func DefaultConfigFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", path.Join(home, configFileName), configFileType), nil
 }
 
 func getConfigFilename(homedir string) string {
@@ -2,20 +2,40 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/steffakasid/trivy-scanner/internal"
 )
 
-func printResultJson(results internal.TrivyResults) {
-	file, _ := json.MarshalIndent(results, "", "  ")
-	if len(viper.GetString(OUTPUT_FILE)) > 0 {
-		if err := ioutil.WriteFile(viper.GetString(OUTPUT_FILE), file, 0644); err != nil {
-			panic(err)
+func printResultJson(w io.Writer, results internal.TrivyResults) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results.ToJSONReport(jsonMetadata()))
+}
+
+func jsonMetadata() internal.JSONMetadata {
+	metadata := internal.JSONMetadata{
+		Tool:         "trivyops",
+		ToolVersion:  version,
+		GeneratedAt:  time.Now().UTC().Truncate(time.Second),
+		JobName:      viper.GetString(internal.JOB_NAME),
+		ArtifactName: viper.GetString(internal.ARTIFACT),
+	}
+	groupIds := []string{}
+	for _, scan := range scans {
+		if scan.Instance != "" {
+			metadata.Instances = append(metadata.Instances, scan.Instance)
 		}
-	} else {
-		fmt.Println(string(file))
+		if scan.ID != "" {
+			groupIds = append(groupIds, scan.ID)
+		}
+	}
+	if len(metadata.Instances) == 0 {
+		metadata.GitLabHost = viper.GetString(internal.GITLAB_HOST)
 	}
+	metadata.GroupID = strings.Join(groupIds, ",")
+	return metadata
 }
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+func printResultJUnit(w io.Writer, results internal.TrivyResults) error {
+	suites, err := results.ToJUnit(viper.GetString(JUNIT_SEVERITY))
+	if err != nil {
+		return fmt.Errorf("failed to create junit report: %w", err)
+	}
+
+	ew := &errWriter{w: w}
+	io.WriteString(ew, xml.Header)
+	enc := xml.NewEncoder(ew)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	io.WriteString(ew, "\n")
+	return ew.err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultJUnit(t *testing.T) {
+	tests := []struct {
+		severity     string
+		wantFailures int
+		wantErr      string
+	}{
+		{severity: "CRITICAL", wantFailures: 2},
+		{severity: "high", wantFailures: 4},
+		{severity: "UNKNOWN", wantFailures: 4},
+		{severity: "SEVERE", wantErr: `failed to create junit report: unknown severity "SEVERE"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			setConfig(t, map[string]any{JUNIT_SEVERITY: tt.severity})
+
+			buf := &bytes.Buffer{}
+			err := printResultJUnit(buf, loadResults(t))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+			suites := internal.JUnitTestSuites{}
+			require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+			assert.Equal(t, 5, suites.Tests)
+			assert.Equal(t, tt.wantFailures, suites.Failures)
+			require.Len(t, suites.Suites, 3)
+			assert.Equal(t, "app", suites.Suites[0].Name)
+			assert.Equal(t, strings.ToUpper(tt.severity), suites.Suites[0].Cases[0].Failure.Type)
+		})
+	}
+}
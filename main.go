@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
@@ -18,22 +17,86 @@ import (
 var version = "0.1-dev"
 
 const (
-	FILTER      = "filter"
-	OUTPUT      = "output"
-	OUTPUT_FILE = "output-file"
-	DAEMON      = "daemon"
-	V           = "v"
-	VV          = "vv"
-	VVV         = "vvv"
-	HELP        = "help"
-	VERSION     = "version"
+	FILTER              = "filter"
+	GROUP               = "group"
+	PROJECT             = "project"
+	TARGETS_FILE        = "targets-file"
+	INCLUDE             = "include"
+	EXCLUDE             = "exclude"
+	DRY_RUN             = "dry-run"
+	CA_FILE             = "ca-file"
+	CLIENT_CERT         = "client-cert"
+	CLIENT_KEY          = "client-key"
+	INSECURE            = "insecure-skip-verify"
+	PROXY               = "proxy"
+	TIMEOUT             = "timeout"
+	TOPIC               = "topic"
+	SEARCH              = "search"
+	LANGUAGE            = "with-programming-language"
+	LAST_ACTIVITY_AFTER = "last-activity-after"
+	VISIBILITY          = "visibility"
+	INCLUDE_ARCHIVED    = "include-archived"
+	EXCLUDE_FORKS       = "exclude-forks"
+	WITH_SHARED         = "with-shared"
+	OUTPUT              = "output"
+	OUTPUT_FILE         = "output-file"
+	COLUMNS             = "columns"
+	TEMPLATE            = "template"
+	NDJSON_PER          = "ndjson-per"
+	SORT_BY             = "sort-by"
+	GROUP_BY            = "group-by"
+	SEVERITY            = "severity"
+	CLASS               = "class"
+	FIXABLE_ONLY        = "fixable-only"
+	ID_REGEX            = "id-regex"
+	PKG_REGEX           = "pkg-regex"
+	JUNIT_SEVERITY      = "junit-severity"
+	DAEMON              = "daemon"
+	DISALLOWED_LICENSES = "disallowed-licenses"
+	V                   = "v"
+	VV                  = "vv"
+	VVV                 = "vvv"
+	HELP                = "help"
+	VERSION             = "version"
 )
 
 func init() {
 	flag.StringP(FILTER, "f", "", "A golang regular expression to filter project name with namespace (e.g. (^.*/groupprefix.+$)|(^.*otherprefix.*))")
-	flag.StringP(OUTPUT, "o", "text", "Define how to output results [text, table, json]")
-	flag.String(OUTPUT_FILE, "", "Define a file to output the result json")
+	flag.StringSlice(GROUP, []string{}, "Group IDs or paths as id-or-path[=label] to scan in addition to the arguments")
+	flag.StringSlice(PROJECT, []string{}, "Project IDs or paths as id-or-path[=label] to scan")
+	flag.String(TARGETS_FILE, "", "A file with one \"group id-or-path[=label]\" or \"project id-or-path[=label]\" per line to scan")
+	flag.StringArray(INCLUDE, []string{}, "Only scan projects matching one of the rules. A rule is a path regex or comma separated conditions [path, topic, visibility, active-within, inactive-for] e.g. path=^group/team/,topic=docker. Can be given multiple times")
+	flag.StringArray(EXCLUDE, []string{}, "Don't scan projects matching one of the rules. Same syntax as --include. Can be given multiple times")
+	flag.String(TOPIC, "", "Only list projects with the GitLab topic (filtered by GitLab)")
+	flag.String(SEARCH, "", "Only list projects matching the search term (filtered by GitLab)")
+	flag.String(LANGUAGE, "", "Only list projects with the programming language (filtered by GitLab, not supported for groups)")
+	flag.String(LAST_ACTIVITY_AFTER, "", "Only list projects with activity after the date (2006-01-02), timestamp (RFC3339) or age (e.g. 90d)")
+	flag.String(VISIBILITY, "", "Only list projects with the visibility [public, internal, private] (filtered by GitLab)")
+	flag.Bool(INCLUDE_ARCHIVED, false, "Also scan archived projects")
+	flag.Bool(EXCLUDE_FORKS, false, "Don't scan forked projects")
+	flag.Bool(WITH_SHARED, true, "Also scan projects of other namespaces which are shared with the group")
+	flag.String(CA_FILE, "", "A PEM CA bundle which is trusted in addition to the system certificates")
+	flag.String(CLIENT_CERT, "", "A PEM client certificate for mutual TLS")
+	flag.String(CLIENT_KEY, "", "The PEM key of the client certificate")
+	flag.Bool(INSECURE, false, "Don't verify the GitLab certificate (only for test instances)")
+	flag.String(PROXY, "", "The HTTP(S) proxy url (default HTTPS_PROXY and NO_PROXY)")
+	flag.Duration(TIMEOUT, 60*time.Second, "The time to wait for the response headers of a single request to GitLab, downloads aren't limited (0 waits forever)")
+	flag.Bool(DRY_RUN, false, "Only list which projects would be scanned without fetching any results")
+	flag.StringSliceP(OUTPUT, "o", []string{"text"}, "Define how to output results as format[=file], can be given multiple times [text, table, json, ndjson, sarif, csv, tsv, html, markdown, template, junit, baseimage]")
+	flag.String(OUTPUT_FILE, "", "Define a file to output the results without own file")
+	flag.StringSlice(COLUMNS, []string{}, "A comma separated list of columns for csv and tsv output [group, instance, label, project, namespace, ref, target, class, id, package, installed, fixed, severity, cvss, title, ignored] (default all except group, instance and label, group is added with --group-by)")
+	flag.String(TEMPLATE, "", "A text/template or html/template (.html, .html.tmpl) file used for template output (default built-in text template)")
+	flag.StringSlice(SEVERITY, []string{}, "Only report findings with one of the severities [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]")
+	flag.StringSlice(CLASS, []string{}, "Only report findings of the classes [vuln, misconfig, secret, license]")
+	flag.Bool(FIXABLE_ONLY, false, "Only report vulnerabilities with a fixed version")
+	flag.String(ID_REGEX, "", "Only report findings with an ID (CVE, misconfiguration, secret rule or license) matching the golang regular expression")
+	flag.String(PKG_REGEX, "", "Only report findings with a package name matching the golang regular expression")
+	flag.String(SORT_BY, internal.SortByName, "Sort projects by [name, critical, high, total, fixable, age]. Projects with equal values are sorted by namespace and name")
+	flag.String(GROUP_BY, "", "Group projects by [namespace, label, instance, os, status]")
+	flag.String(NDJSON_PER, ndjsonPerProject, "Write one ndjson line per [project, finding]")
+	flag.String(JUNIT_SEVERITY, "HIGH", "The minimum severity [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL] of findings which let a junit testcase fail")
 	flag.BoolP(DAEMON, "d", false, "Set trivyops to deamon mode to be able to publish prometheus metrics")
+	flag.StringSlice(DISALLOWED_LICENSES, []string{}, "A comma separated list of license names (e.g. GPL-3.0,AGPL-3.0) which are not allowed. trivyops exits with 1 if one is found")
 	flag.Bool(V, false, "Get details")
 	flag.Bool(VV, false, "Get more details")
 	flag.Bool(VVV, false, "Get even more details")
@@ -50,14 +113,19 @@ scans all subgroups and prints out a result a GitLab CI trivy scan job and check
 if there is a .trivyignore defined in the default branch.
 
 Usage:
-  trivyops [flags] GITLAB_GROUP_ID
+  trivyops [flags] [GITLAB_GROUP_ID...]
+  trivyops doctor [flags] [GITLAB_GROUP_ID...]	- check the GitLab access instead of scanning
+  trivyops config validate|show|init [FILE]	- validate the config, show the effective config or write a starter config file
 
 Variables:
   - JOB_NAME  			- The gitlab ci jobname to check [Default "scan_oci_image_trivy"]
   - ARTIFACT		    - The artifact filename of the trivy result [Default: "trivy-results.json"]
   - GITLAB_TOKEN		- the GitLab token to access the Gitlab instance
+  - GITLAB_TOKEN_TYPE	- the type of the token [token, job-token, oauth] [Default: token]
+  - GITLAB_TOKEN_FILE	- a file to read the token from, it's re-read when it changes
+  - CI_JOB_TOKEN		- used as job token if neither GITLAB_TOKEN nor GITLAB_TOKEN_FILE is set
   - GITLAB_HOST			- the GitLab host which should be accessed [Default: https://gitlab.com]
-  - GITLAB_GROUP_ID		- the GitLab group ID to scan (only be used if not given per argument)
+  - GITLAB_GROUP_ID		- the GitLab group ID to scan (only be used if no group or project is given)
   - LOG_LEVEL			- the log level to use [Default: info]
   - METRICS_PORT		- the metrics endpoint when running in daemon mode [Default: 2112]
   - METRICS_CRON		- the cron string used to define how often metrics results are gathered from GitLab [Default: @every 6h]
@@ -65,8 +133,28 @@ Variables:
 Examples:
   trivyops 1234    					- get all trivy results from 1234
   trivyops 1234 --filter ^blub.*	- get all trivy results from 1234 where name starts with blub
+  trivyops 1234 --include path=^group/team/ --exclude inactive-for=365d --dry-run	- list which projects of the team would be scanned
+  trivyops 1234 --topic docker --last-activity-after 90d	- let GitLab only list recently active projects with the topic docker
+  trivyops 1234 my/group=platform --project my/standalone/app	- scan two groups and a single project, labelling the projects with their group
+  trivyops 1234 --include-archived --exclude-forks --with-shared=false	- scan archived projects too but skip forks and shared projects
+  trivyops --group-by instance		- scan all instances configured in the config file and group the projects by instance
+  trivyops 1234 --ca-file ca.pem --proxy http://proxy:3128	- access a self-managed GitLab with an internal CA behind a proxy
+  trivyops doctor 1234				- check connectivity, token, access to 1234 and the artifact download
+  trivyops config show --severity HIGH	- show every setting with its source (flag, env, config or default)
   trivyops 1234 -o table			- output results as table (works well with less results)
+  trivyops 1234 -o table -o json=out.json -o html=report.html	- output a table and write json and html files
+  trivyops 1234 -o ndjson --ndjson-per finding	- stream one json line per finding while scanning
+  trivyops 1234 --sort-by critical --group-by namespace	- sort projects by critical vulnerabilities within each namespace
+  trivyops 1234 --severity CRITICAL,HIGH --class vuln --fixable-only	- only report fixable critical and high vulnerabilities
+  trivyops 1234 -o baseimage		- group projects by OS family, version and image digest
+  trivyops 1234 -o sarif --output-file trivyops.sarif	- write a SARIF 2.1.0 report with one run per project
+  trivyops 1234 -o csv --columns project,id,severity	- write one row per finding with the given columns
+  trivyops 1234 -o html --output-file report.html	- write a self-contained HTML report with a summary dashboard
+  trivyops 1234 -o markdown -v		- print GitLab flavoured markdown e.g. for issues and wiki pages
+  trivyops 1234 -o template --template report.tmpl	- render the results with a custom go template
+  trivyops 1234 -o junit --output-file junit.xml	- write a JUnit report with one testsuite per project e.g. for GitLab test reports
   trivyops 1234 -v					- get more details
+  trivyops 1234 --disallowed-licenses GPL-3.0,AGPL-3.0	- fail if one of the licenses is found
 
 Flags:`)
 
@@ -79,10 +167,13 @@ Flags:`)
 		logger.Error(err)
 	}
 
-	internal.InitConfig()
+	configErr = internal.InitConfig()
 }
 
-var scan *internal.Scan
+var (
+	scans     []*internal.Scan
+	configErr error
+)
 
 func main() {
 
@@ -93,38 +184,52 @@ func main() {
 	} else if viper.GetBool(HELP) {
 		flag.Usage()
 	} else {
-		args, gitToken, gitHost := validateArgsNEnv()
-
-		logger.Debugf("Creating client for host %s", gitHost)
-		git, err := gitlab.NewClient(gitToken, gitlab.WithBaseURL(gitHost))
+		cmd, args := command(flag.Args())
+		if cmd == configCommand {
+			doConfig(args)
+			return
+		}
+		if configErr != nil {
+			logger.Fatalf("%v, check it with trivyops config validate", configErr)
+		}
+		instances, err := internal.LoadInstances()
 		if err != nil {
-			logger.Fatalf("failed to create GitLab client: %v", err)
+			logger.Fatalf("Error reading instances: %v", err)
 		}
-
-		client := &internal.GitLabClient{
-			GroupsClient:    git.Groups,
-			ProjectsClient:  git.Projects,
-			JobsClient:      git.Jobs,
-			PipelinesClient: git.Pipelines,
-			RepositoryFiles: git.RepositoryFiles,
+		targetsFile := ""
+		if len(instances) == 0 {
+			args, credentials, gitHost := validateArgsNEnv(args)
+			groups := append(args, viper.GetStringSlice(GROUP)...)
+			projects := viper.GetStringSlice(PROJECT)
+			targetsFile = viper.GetString(TARGETS_FILE)
+			if len(groups) == 0 && len(projects) == 0 && targetsFile == "" && viper.GetString(internal.GITLAB_GROUP_ID) != "" {
+				groups = []string{viper.GetString(internal.GITLAB_GROUP_ID)}
+			}
+			instances = []internal.Instance{{Host: gitHost, Credentials: credentials, Groups: groups, Projects: projects}}
+		} else if len(args) > 0 || len(viper.GetStringSlice(GROUP)) > 0 || len(viper.GetStringSlice(PROJECT)) > 0 || viper.GetString(TARGETS_FILE) != "" {
+			logger.Fatalf("Groups and projects have to be configured per instance if %s are configured", internal.INSTANCES)
 		}
 
-		groupId := ""
-		if len(args) == 1 {
-			groupId = args[0]
-		} else {
-			groupId = viper.GetString(internal.GITLAB_GROUP_ID)
+		if cmd == doctorCommand {
+			doDoctor(instances, targetsFile)
+			return
 		}
-		scan, err = internal.InitScanner(groupId,
-			viper.GetString(internal.JOB_NAME),
-			viper.GetString(internal.ARTIFACT),
-			viper.GetString(FILTER),
-			client)
-		if err != nil {
-			logger.Fatalf("Error initializing scanner: %v", err)
+
+		for _, instance := range instances {
+			git, err := newGitLabClient(instance)
+			if err != nil {
+				logger.Fatalf("Error initializing scanner: %v", err)
+			}
+			s, err := newScan(instance, git, targetsFile)
+			if err != nil {
+				logger.Fatalf("Error initializing scanner: %v", err)
+			}
+			scans = append(scans, s)
 		}
 
-		if viper.GetBool(DAEMON) {
+		if viper.GetBool(DRY_RUN) {
+			doDryRun()
+		} else if viper.GetBool(DAEMON) {
 			startDaemon()
 		} else {
 			doScanWithOutput()
@@ -132,50 +237,161 @@ func main() {
 	}
 }
 
-func validateArgsNEnv() ([]string, string, string) {
-	args := flag.Args()
+// command returns the subcommand if the first argument is one and the
+// remaining arguments.
+func command(args []string) (string, []string) {
+	if len(args) > 0 && (args[0] == doctorCommand || args[0] == configCommand) {
+		return args[0], args[1:]
+	}
+	return "", args
+}
 
-	if len(args) > 1 {
-		log.Printf("More then one argument provided: %s\n", args)
-		flag.Usage()
-		os.Exit(1)
+// newGitLabClient creates the GitLab client of an instance.
+func newGitLabClient(instance internal.Instance) (*gitlab.Client, error) {
+	logger.Debugf("Creating client for host %s", instance.Host)
+	httpClient, err := internal.NewHTTPClient(instance.HTTP.Merge(internal.HTTPConfig{
+		CAFile:             viper.GetString(CA_FILE),
+		ClientCert:         viper.GetString(CLIENT_CERT),
+		ClientKey:          viper.GetString(CLIENT_KEY),
+		InsecureSkipVerify: viper.GetBool(INSECURE),
+		Proxy:              viper.GetString(PROXY),
+		Timeout:            viper.GetDuration(TIMEOUT),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	git, err := instance.Credentials.NewGitLabClient(gitlab.WithBaseURL(instance.Host), gitlab.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
+	return git, nil
+}
 
-	gitToken := viper.GetString("GITLAB_TOKEN")
-	if gitToken == "" {
-		logger.Fatal("no GITLAB_TOKEN env var set!")
+// newScan creates the scanner for the groups and projects of a GitLab
+// instance.
+func newScan(instance internal.Instance, git *gitlab.Client, targetsFile string) (*internal.Scan, error) {
+	listOptions, err := internal.NewProjectListOptions(
+		viper.GetString(TOPIC),
+		viper.GetString(SEARCH),
+		viper.GetString(LANGUAGE),
+		viper.GetString(LAST_ACTIVITY_AFTER),
+		viper.GetString(VISIBILITY))
+	if err != nil {
+		return nil, fmt.Errorf("invalid project list options: %w", err)
 	}
+	listOptions.IncludeArchived = viper.GetBool(INCLUDE_ARCHIVED)
+	listOptions.ExcludeForks = viper.GetBool(EXCLUDE_FORKS)
+	listOptions.ExcludeShared = !viper.GetBool(WITH_SHARED)
 
-	gitHost := viper.GetString("GITLAB_HOST")
-	return args, gitToken, gitHost
-}
+	client := &internal.GitLabClient{
+		GroupsClient:    git.Groups,
+		ProjectsClient:  git.Projects,
+		JobsClient:      git.Jobs,
+		PipelinesClient: git.Pipelines,
+		RepositoryFiles: git.RepositoryFiles,
+		ListOptions:     listOptions,
+	}
 
-func doScanWithOutput() {
-	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	s.Start()
+	targets, err := internal.ParseTargets(instance.Groups, instance.Projects, targetsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets: %w", err)
+	}
+	groupIds := []string{}
+	for _, target := range targets {
+		if target.Kind == internal.TargetGroup {
+			groupIds = append(groupIds, target.ID)
+		}
+	}
+	s, err := internal.InitScanner(strings.Join(groupIds, ","),
+		viper.GetString(internal.JOB_NAME),
+		viper.GetString(internal.ARTIFACT),
+		viper.GetString(FILTER),
+		client)
+	if err != nil {
+		return nil, err
+	}
+	s.Instance = instance.Name
+	s.Targets = targets
+	s.Rules, err = internal.NewProjectRules(viper.GetStringSlice(INCLUDE), viper.GetStringSlice(EXCLUDE))
+	if err != nil {
+		return nil, fmt.Errorf("invalid project rules: %w", err)
+	}
+	s.FindingFilter, err = internal.NewFindingFilter(
+		viper.GetStringSlice(SEVERITY),
+		viper.GetStringSlice(CLASS),
+		viper.GetBool(FIXABLE_ONLY),
+		viper.GetString(ID_REGEX),
+		viper.GetString(PKG_REGEX))
+	if err != nil {
+		return nil, fmt.Errorf("invalid finding filter: %w", err)
+	}
+	return s, nil
+}
 
-	var (
-		projs []*gitlab.Project
-		err   error
-	)
+func validateArgsNEnv(args []string) ([]string, internal.Credentials, string) {
+	credentials, err := internal.CredentialsFromConfig().Resolve()
+	if err != nil {
+		logger.Fatal(err)
+	}
 
-	projs, err = scan.GitLabClient.GetProjects(scan.ID)
+	gitHost := viper.GetString("GITLAB_HOST")
+	return args, credentials, gitHost
+}
 
+func doScanWithOutput() {
+	outputs, err := parseOutputs(viper.GetStringSlice(OUTPUT), viper.GetString(OUTPUT_FILE))
 	if err != nil {
 		logger.Fatal(err)
 	}
-	trivyResults, err := scan.ScanProjects(projs)
+	if _, err := ndjsonPer(); err != nil {
+		logger.Fatal(err)
+	}
+
+	// validate the sort and group keys before scanning
+	if err := (internal.TrivyResults{}).Order(viper.GetString(SORT_BY), viper.GetString(GROUP_BY)); err != nil {
+		logger.Fatal(err)
+	}
+	streams, err := openStreams(outputs)
 	if err != nil {
-		logger.Fatalf("Failed to scan trivy results: %s!", err)
+		logger.Fatal(err)
+	}
+
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	s.Start()
+
+	trivyResults := internal.TrivyResults{}
+	disallowedLicenses := 0
+	collect := needsAllResults(outputs)
+	for _, scan := range scans {
+		projs, err := scan.ListProjects()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		err = scan.StreamProjects(projs, func(result internal.TrivyResults) error {
+			disallowedLicenses += result.CheckLicenses(viper.GetStringSlice(DISALLOWED_LICENSES))
+			if collect {
+				trivyResults = append(trivyResults, result...)
+			}
+			return streams.Write(result)
+		})
+		if err != nil {
+			logger.Fatalf("Failed to scan trivy results: %s!", err)
+		}
 	}
-	trivyResults.Check()
 	s.Stop()
-	fmt.Println()
-	if strings.ToLower(viper.GetString(OUTPUT)) == "table" {
-		printResultTbl(trivyResults)
-	} else if strings.ToLower(viper.GetString(OUTPUT)) == "json" {
-		printResultJson(trivyResults)
-	} else {
-		printResultTxt(trivyResults)
+	fmt.Fprintln(os.Stderr)
+	if err := trivyResults.Order(viper.GetString(SORT_BY), viper.GetString(GROUP_BY)); err != nil {
+		logger.Fatal(err)
+	}
+	if err := writeOutputs(outputs, trivyResults); err != nil {
+		logger.Fatal(err)
+	}
+	if err := streams.Close(); err != nil {
+		logger.Fatal(err)
+	}
+
+	if disallowedLicenses > 0 {
+		logger.Errorf("Found %d disallowed licenses!", disallowedLicenses)
+		os.Exit(1)
 	}
 }
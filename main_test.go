@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update the golden files with UPDATE_GOLDEN=1 go test . because the flags
+// of the tests are parsed by init.
+var update = os.Getenv("UPDATE_GOLDEN") != ""
+
+// loadResults reads the results of three projects with vulnerabilities,
+// misconfigurations, secrets and licenses from test/results.json.
+func loadResults(t *testing.T) internal.TrivyResults {
+	bt, err := os.ReadFile("test/results.json")
+	require.NoError(t, err)
+	results := internal.TrivyResults{}
+	require.NoError(t, json.Unmarshal(bt, &results))
+	// the results of a job share the provenance like after a scan
+	provenances := map[internal.Provenance]*internal.Provenance{}
+	for _, result := range results {
+		for i, res := range result.ReportResult {
+			if res.Provenance == nil {
+				continue
+			}
+			if prov, ok := provenances[*res.Provenance]; ok {
+				result.ReportResult[i].Provenance = prov
+			} else {
+				provenances[*res.Provenance] = res.Provenance
+			}
+		}
+	}
+	results.Check()
+	return results
+}
+
+// setConfig overrides the settings for the test and restores them afterwards.
+func setConfig(t *testing.T, settings map[string]any) {
+	for key, value := range settings {
+		old := viper.Get(key)
+		t.Cleanup(func() { viper.Set(key, old) })
+		viper.Set(key, value)
+	}
+}
+
+func assertGolden(t *testing.T, file string, actual string) {
+	if update {
+		require.NoError(t, os.WriteFile(file, []byte(actual), 0644))
+	}
+	golden, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), actual)
+}
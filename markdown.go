@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+var mdSeverityColors = map[string]string{
+	"CRITICAL": "8b0000",
+	"HIGH":     "d1242f",
+	"MEDIUM":   "bf8700",
+	"LOW":      "0969da",
+}
+
+// printResultMarkdown prints the results as GitLab flavoured markdown which
+// can be pasted into issues and wiki pages. The details follow the same
+// verbosity levels as printResultTxt.
+func printResultMarkdown(w io.Writer, results internal.TrivyResults) error {
+	ew := &errWriter{w: w}
+	w = ew
+
+	fmt.Fprintln(w, "# Trivy results")
+	fmt.Fprintln(w)
+	grouped := isGrouped(results)
+	if grouped {
+		fmt.Fprint(w, "| Group ")
+	}
+	fmt.Fprintln(w, "| Project | Scanned Packages | Vulnerabilities | Secrets | Misconfigurations failed | Licenses | .trivyignore |")
+	if grouped {
+		fmt.Fprint(w, "|---")
+	}
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---|")
+	for _, projResult := range results {
+		if grouped {
+			fmt.Fprintf(w, "| %s ", mdEscape(projResult.Group))
+		}
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %t |\n",
+			mdEscape(projResult.ProjName),
+			len(projResult.ReportResult),
+			projResult.Vulnerabilities.Count,
+			projResult.Vulnerabilities.Secrets,
+			projResult.Vulnerabilities.Misconfigurations.Fail,
+			projResult.Vulnerabilities.Licenses.Count,
+			(len(projResult.Ignore) > 0))
+	}
+
+	if viper.GetBool(V) || viper.GetBool(VV) || viper.GetBool(VVV) {
+		for i, projResult := range results {
+			if startsGroup(results, i) {
+				fmt.Fprintln(w)
+				fmt.Fprintf(w, "## %s\n", mdEscape(projResult.Group))
+			}
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, "<details><summary><b>%s</b> %s %s</summary>\n\n",
+				mdEscape(projResult.ProjName),
+				mdBadge("CRITICAL", projResult.Vulnerabilities.Critical),
+				mdBadge("HIGH", projResult.Vulnerabilities.High))
+			printResultDetailsMarkdown(w, projResult.ReportResult)
+			fmt.Fprintln(w, "</details>")
+		}
+	}
+	printSecretsMarkdown(w, results)
+	printLicensesMarkdown(w, results)
+	return ew.err
+}
+
+func printResultDetailsMarkdown(w io.Writer, res internal.Results) {
+	var prov *internal.Provenance
+	if viper.GetBool(V) {
+		for _, tgt := range res {
+			if tgt.Provenance != nil && tgt.Provenance != prov {
+				prov = tgt.Provenance
+				fmt.Fprintf(w, "Source: %s\n\n", mdEscape(prov.String()))
+			}
+		}
+		fmt.Fprintln(w, "| Target | Critical | High | Medium | Low | Unkown | Secrets | Misconfigurations Pass/Fail/Exception |")
+		fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|---|")
+		for _, tgt := range res {
+			crit, hi, med, lo, un := internal.GetSummary(tgt.Vulnerabilities)
+			pass, fail, exception := internal.GetMisconfSummary(tgt.Misconfigurations)
+			fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %d | %d/%d/%d |\n",
+				mdEscape(tgt.Target), crit, hi, med, lo, un, len(tgt.Secrets), pass, fail, exception)
+		}
+		fmt.Fprintln(w)
+		return
+	}
+
+	for _, tgt := range res {
+		if tgt.Provenance != nil && tgt.Provenance != prov {
+			prov = tgt.Provenance
+			fmt.Fprintf(w, "Source: %s\n\n", mdEscape(prov.String()))
+		}
+		fmt.Fprintf(w, "#### %s\n\n", mdEscape(tgt.Target))
+		if len(tgt.Vulnerabilities) > 0 {
+			printVulnerabilitiesMarkdown(w, tgt.Vulnerabilities)
+		}
+		if len(tgt.Misconfigurations) > 0 {
+			printMisconfigurationsMarkdown(w, tgt.Target, tgt.Misconfigurations)
+		}
+		if len(tgt.Secrets) > 0 {
+			fmt.Fprintf(w, "%d secrets found, see secrets below!\n\n", len(tgt.Secrets))
+		}
+		if len(tgt.Licenses) > 0 {
+			fmt.Fprintf(w, "%d licenses found, see licenses below!\n\n", len(tgt.Licenses))
+		}
+		if len(tgt.Vulnerabilities) == 0 && len(tgt.Misconfigurations) == 0 && len(tgt.Secrets) == 0 && len(tgt.Licenses) == 0 {
+			fmt.Fprintln(w, "No vulnerabilities found!")
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func printVulnerabilitiesMarkdown(w io.Writer, vullies []types.DetectedVulnerability) {
+	if viper.GetBool(VVV) {
+		fmt.Fprintln(w, "| Package | ID | Severity | Title | IsFixable | InstalledVersion | FixedVersion |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---|---|")
+	} else {
+		fmt.Fprintln(w, "| Package | ID | Severity | Title | IsFixable |")
+		fmt.Fprintln(w, "|---|---|---|---|---|")
+	}
+	for _, vulli := range vullies {
+		id := mdEscape(vulli.VulnerabilityID)
+		if vulli.PrimaryURL != "" {
+			id = fmt.Sprintf("[%s](%s)", id, vulli.PrimaryURL)
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %t |",
+			mdEscape(vulli.PkgName),
+			id,
+			mdBadge(vulli.Severity, -1),
+			mdEscape(vulli.Title),
+			(vulli.FixedVersion != ""))
+		if viper.GetBool(VVV) {
+			fmt.Fprintf(w, " %s | %s |", mdEscape(vulli.InstalledVersion), mdEscape(vulli.FixedVersion))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+func printMisconfigurationsMarkdown(w io.Writer, target string, misconfs []types.DetectedMisconfiguration) {
+	pass, fail, exception := internal.GetMisconfSummary(misconfs)
+	fmt.Fprintf(w, "Misconfigurations Pass: %d, Fail: %d, Exception: %d\n\n", pass, fail, exception)
+	if viper.GetBool(VVV) {
+		fmt.Fprintln(w, "| ID | AVD ID | Severity | Status | Message | Location | Resolution |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---|---|")
+	} else {
+		fmt.Fprintln(w, "| ID | AVD ID | Severity | Status | Message | Location |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---|")
+	}
+	for _, misconf := range misconfs {
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |",
+			mdEscape(misconf.ID),
+			mdEscape(misconf.AVDID),
+			mdBadge(misconf.Severity, -1),
+			misconf.Status,
+			mdEscape(misconf.Message),
+			mdEscape(internal.MisconfLocation(target, misconf)))
+		if viper.GetBool(VVV) {
+			fmt.Fprintf(w, " %s |", mdEscape(misconf.Resolution))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+func printSecretsMarkdown(w io.Writer, results internal.TrivyResults) {
+	header := false
+	for _, projResult := range results {
+		for _, secret := range projResult.Secrets {
+			if !header {
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "## Secrets")
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "| Project | Location | Rule | Category | Severity | Match |")
+				fmt.Fprintln(w, "|---|---|---|---|---|---|")
+				header = true
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | `%s` |\n",
+				mdEscape(projResult.ProjName),
+				mdEscape(secret.Location()),
+				mdEscape(secret.RuleID),
+				mdEscape(string(secret.Category)),
+				mdBadge(secret.Severity, -1),
+				strings.ReplaceAll(mdEscape(secret.Match), "`", "'"))
+		}
+	}
+}
+
+// printLicensesMarkdown prints all forbidden, restricted and disallowed
+// licenses. With -vv or -vvv all license findings are printed.
+func printLicensesMarkdown(w io.Writer, results internal.TrivyResults) {
+	header := false
+	for _, projResult := range results {
+		for _, license := range projResult.Licenses {
+			if !license.IsRestricted() && !viper.GetBool(VV) && !viper.GetBool(VVV) {
+				continue
+			}
+			if !header {
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "## Licenses")
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "| Project | Package / File | License | Category | Severity | Disallowed |")
+				fmt.Fprintln(w, "|---|---|---|---|---|---|")
+				header = true
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %t |\n",
+				mdEscape(projResult.ProjName),
+				mdEscape(license.Location()),
+				mdEscape(license.Name),
+				mdEscape(string(license.Category)),
+				mdBadge(license.Severity, -1),
+				license.Disallowed)
+		}
+	}
+}
+
+// mdBadge returns a shields.io badge for the given severity. If count is not
+// negative it's shown as badge value.
+func mdBadge(severity string, count int) string {
+	color, ok := mdSeverityColors[strings.ToUpper(severity)]
+	if !ok {
+		color = "6e7781"
+	}
+	label := url.PathEscape(strings.ReplaceAll(severity, "-", "--"))
+	if count < 0 {
+		return fmt.Sprintf("![%s](https://img.shields.io/badge/%s-%s)", severity, label, color)
+	}
+	return fmt.Sprintf("![%s %d](https://img.shields.io/badge/%s-%d-%s)", severity, count, label, count, color)
+}
+
+// mdEscape escapes characters which would break a markdown table cell.
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
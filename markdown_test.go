@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity string
+		golden    string
+	}{
+		{name: "default", golden: "test/markdown.golden.md"},
+		{name: "v", verbosity: V, golden: "test/markdown-v.golden.md"},
+		{name: "vvv", verbosity: VVV, golden: "test/markdown-vvv.golden.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]any{V: false, VV: false, VVV: false}
+			if tt.verbosity != "" {
+				settings[tt.verbosity] = true
+			}
+			setConfig(t, settings)
+			results := loadResults(t)
+			require.NoError(t, results.Order(internal.SortByName, internal.GroupByNamespace))
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, printResultMarkdown(buf, results))
+			assertGolden(t, tt.golden, buf.String())
+		})
+	}
+}
+
+func TestMdEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "a | b", want: `a \| b`},
+		{in: "line1\r\nline2", want: "line1<br>line2"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, mdEscape(tt.in))
+	}
+}
+
+func TestMdBadge(t *testing.T) {
+	assert.Equal(t, "![HIGH](https://img.shields.io/badge/HIGH-d1242f)", mdBadge("HIGH", -1))
+	assert.Equal(t, "![CRITICAL 2](https://img.shields.io/badge/CRITICAL-2-8b0000)", mdBadge("CRITICAL", 2))
+	assert.Equal(t, "![NOT-SET](https://img.shields.io/badge/NOT--SET-6e7781)", mdBadge("NOT-SET", -1))
+}
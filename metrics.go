@@ -33,14 +33,20 @@ func startDaemon() {
 }
 
 func fetchResults() {
-	projs, err := scan.GitLabClient.GetProjects(scan.ID)
-	if err != nil {
-		logger.Errorf("failed getting projects: %v", err)
-	}
-	trivyResults, err = scan.ScanProjects(projs)
-	if err != nil {
-		logger.Errorf("failed scan projects: %v", err)
+	results := internal.TrivyResults{}
+	for _, scan := range scans {
+		projs, err := scan.ListProjects()
+		if err != nil {
+			logger.Errorf("failed getting projects: %v", err)
+		}
+		scanResults, err := scan.ScanProjects(projs)
+		if err != nil {
+			logger.Errorf("failed scan projects: %v", err)
+		}
+		results = append(results, scanResults...)
 	}
+	trivyResults = results
+	trivyResults.CheckLicenses(viper.GetStringSlice(DISALLOWED_LICENSES))
 	updateRegister()
 }
 
@@ -59,6 +65,8 @@ func updateRegister() {
 		labels := map[string]string{
 			"project":          trivy.ProjName,
 			"id":               strconv.Itoa(trivy.ProjId),
+			"gitlab_instance":  trivy.Instance,
+			"group":            trivy.Label,
 			"scanned_job_name": viper.GetString(internal.JOB_NAME),
 			"trivyignore":      trivyIgnore,
 		}
@@ -87,13 +95,82 @@ func updateRegister() {
 			Help:        "this is a cached result and will updated every hour",
 			ConstLabels: labels,
 		})
+		delete(labels, "type")
+		gaugeSecrets := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "trivy",
+			Subsystem:   "exporter",
+			Name:        "secrets",
+			Help:        "number of leaked secrets, this is a cached result and will updated every hour",
+			ConstLabels: labels,
+		})
+		misconfGauges := map[string]int{
+			"pass":      trivy.Vulnerabilities.Misconfigurations.Pass,
+			"fail":      trivy.Vulnerabilities.Misconfigurations.Fail,
+			"exception": trivy.Vulnerabilities.Misconfigurations.Exception,
+		}
+		for status, count := range misconfGauges {
+			labels["status"] = status
+			gaugeMisconf := prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace:   "trivy",
+				Subsystem:   "exporter",
+				Name:        "misconfigurations",
+				Help:        "number of misconfigurations by status, this is a cached result and will updated every hour",
+				ConstLabels: labels,
+			})
+			gaugeMisconf.Set(float64(count))
+			registeredGauges = append(registeredGauges, gaugeMisconf)
+			reg.MustRegister(gaugeMisconf)
+		}
+		delete(labels, "status")
+
+		for category, count := range trivy.Vulnerabilities.Licenses.Category {
+			labels["category"] = category
+			gaugeLicenses := prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace:   "trivy",
+				Subsystem:   "exporter",
+				Name:        "licenses",
+				Help:        "number of license findings by category, this is a cached result and will updated every hour",
+				ConstLabels: labels,
+			})
+			gaugeLicenses.Set(float64(count))
+			registeredGauges = append(registeredGauges, gaugeLicenses)
+			reg.MustRegister(gaugeLicenses)
+		}
+		delete(labels, "category")
+		for severity, count := range trivy.Vulnerabilities.Licenses.Severity {
+			labels["severity"] = severity
+			gaugeLicenseSeverity := prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace:   "trivy",
+				Subsystem:   "exporter",
+				Name:        "licenses_by_severity",
+				Help:        "number of license findings by severity, this is a cached result and will updated every hour",
+				ConstLabels: labels,
+			})
+			gaugeLicenseSeverity.Set(float64(count))
+			registeredGauges = append(registeredGauges, gaugeLicenseSeverity)
+			reg.MustRegister(gaugeLicenseSeverity)
+		}
+		delete(labels, "severity")
+		gaugeDisallowed := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "trivy",
+			Subsystem:   "exporter",
+			Name:        "disallowed_licenses",
+			Help:        "number of disallowed license findings, this is a cached result and will updated every hour",
+			ConstLabels: labels,
+		})
+		gaugeDisallowed.Set(float64(trivy.Vulnerabilities.Licenses.Disallowed))
+		registeredGauges = append(registeredGauges, gaugeDisallowed)
+		reg.MustRegister(gaugeDisallowed)
+
 		gaugeTotal.Set(float64(trivy.Vulnerabilities.Count))
 		gaugeCritical.Set(float64(trivy.Vulnerabilities.Critical))
 		gaugeHigh.Set(float64(trivy.Vulnerabilities.High))
-		registeredGauges = append(registeredGauges, gaugeTotal, gaugeCritical, gaugeHigh)
+		gaugeSecrets.Set(float64(trivy.Vulnerabilities.Secrets))
+		registeredGauges = append(registeredGauges, gaugeTotal, gaugeCritical, gaugeHigh, gaugeSecrets)
 		reg.MustRegister(gaugeTotal)
 		reg.MustRegister(gaugeCritical)
 		reg.MustRegister(gaugeHigh)
+		reg.MustRegister(gaugeSecrets)
 	}
 }
 
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+const (
+	ndjsonPerProject = "project"
+	ndjsonPerFinding = "finding"
+)
+
+// printResultNdjson writes one json object per project or per finding and
+// line. Projects are written with the project object of the json schema,
+// findings with the finding object plus the project they belong to.
+func printResultNdjson(w io.Writer, results internal.TrivyResults) error {
+	per, err := ndjsonPer()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, proj := range results.ToJSONReport(internal.JSONMetadata{}).Projects {
+		if per == ndjsonPerProject {
+			if err := enc.Encode(proj); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, finding := range proj.ProjectFindings() {
+			if err := enc.Encode(finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ndjsonPer returns the value of --ndjson-per or an error if it's unknown.
+func ndjsonPer() (string, error) {
+	per := strings.ToLower(viper.GetString(NDJSON_PER))
+	if per != ndjsonPerProject && per != ndjsonPerFinding {
+		return "", fmt.Errorf("unknown value %q for --%s, use %s or %s", per, NDJSON_PER, ndjsonPerProject, ndjsonPerFinding)
+	}
+	return per, nil
+}
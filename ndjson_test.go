@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultNdjson(t *testing.T) {
+	tests := []struct {
+		per       string
+		wantLines int
+		wantFirst map[string]any
+		wantErr   string
+	}{
+		{per: "project", wantLines: 3, wantFirst: map[string]any{"id": float64(1), "name": "app", "namespace": "group/team"}},
+		{per: "Finding", wantLines: 7, wantFirst: map[string]any{"projectId": float64(1), "project": "app", "class": "vuln", "id": "CVE-2024-0001"}},
+		{per: "target", wantErr: `unknown value "target" for --ndjson-per, use project or finding`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.per, func(t *testing.T) {
+			setConfig(t, map[string]any{NDJSON_PER: tt.per})
+
+			buf := &bytes.Buffer{}
+			err := printResultNdjson(buf, loadResults(t))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			lines := readLines(t, buf.Bytes())
+			require.Len(t, lines, tt.wantLines)
+			for key, value := range tt.wantFirst {
+				assert.Equal(t, value, lines[0][key], key)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	setConfig(t, map[string]any{NDJSON_PER: "project"})
+	file := filepath.Join(t.TempDir(), "projects.ndjson")
+	s, err := openStreams([]output{{format: "json", file: "report.json"}, {format: "ndjson", file: file}})
+	require.NoError(t, err)
+	results := loadResults(t)
+	for i := range results {
+		require.NoError(t, s.Write(results[i:i+1]))
+	}
+	require.NoError(t, s.Close())
+
+	bt, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := readLines(t, bt)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "lib", lines[2]["name"])
+	assert.NoFileExists(t, "report.json")
+}
+
+func TestNeedsAllResults(t *testing.T) {
+	assert.False(t, needsAllResults([]output{{format: "ndjson"}}))
+	assert.True(t, needsAllResults([]output{{format: "ndjson"}, {format: "json", file: "report.json"}}))
+}
+
+func readLines(t *testing.T, bt []byte) []map[string]any {
+	lines := []map[string]any{}
+	scanner := bufio.NewScanner(bytes.NewReader(bt))
+	for scanner.Scan() {
+		line := map[string]any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+// templatesFS holds the built-in templates and assets of the html report.
+//
+//go:embed templates/*
+var templatesFS embed.FS
+
+type printer func(w io.Writer, results internal.TrivyResults) error
+
+var printers = map[string]printer{
+	"text":  printResultTxt,
+	"table": printResultTbl,
+	"json":  printResultJson,
+	"sarif": printResultSarif,
+	"csv": func(w io.Writer, results internal.TrivyResults) error {
+		return printResultCsv(w, results, ',')
+	},
+	"tsv": func(w io.Writer, results internal.TrivyResults) error {
+		return printResultCsv(w, results, '\t')
+	},
+	"html":      printResultHtml,
+	"markdown":  printResultMarkdown,
+	"template":  printResultTemplate,
+	"junit":     printResultJUnit,
+	"baseimage": printResultBaseImages,
+	"ndjson":    printResultNdjson,
+}
+
+// streamingFormats are written project by project while scanning instead of
+// after the scan has finished.
+var streamingFormats = map[string]bool{
+	"ndjson": true,
+}
+
+// output defines one output format and the file it's written to. An empty
+// file means stdout.
+type output struct {
+	format string
+	file   string
+}
+
+func (o output) String() string {
+	if o.file == "" {
+		return o.format
+	}
+	return fmt.Sprintf("%s=%s", o.format, o.file)
+}
+
+// parseOutputs parses the output definitions given as format[=file]. Outputs
+// without a file are written to defaultFile or stdout if defaultFile is empty.
+func parseOutputs(definitions []string, defaultFile string) ([]output, error) {
+	outputs := []output{}
+	files := map[string]output{}
+	for _, definition := range definitions {
+		for _, def := range strings.Split(definition, ",") {
+			def = strings.TrimSpace(def)
+			if def == "" {
+				continue
+			}
+			format, file, _ := strings.Cut(def, "=")
+			o := output{format: strings.ToLower(strings.TrimSpace(format)), file: strings.TrimSpace(file)}
+			if o.file == "" {
+				o.file = defaultFile
+			}
+			if _, ok := printers[o.format]; !ok {
+				return nil, fmt.Errorf("unknown output format %q, valid formats are %s", o.format, strings.Join(outputFormats(), ", "))
+			}
+			if o.file != "" {
+				if other, ok := files[o.file]; ok {
+					return nil, fmt.Errorf("outputs %s and %s write to the same file", other, o)
+				}
+				files[o.file] = o
+			}
+			outputs = append(outputs, o)
+		}
+	}
+	if len(outputs) == 0 {
+		outputs = append(outputs, output{format: "text", file: defaultFile})
+	}
+	return outputs, nil
+}
+
+func outputFormats() []string {
+	return internal.SortedKeys(printers)
+}
+
+// writeOutputs writes the results in all given outputs which are not
+// streamed.
+func writeOutputs(outputs []output, results internal.TrivyResults) error {
+	for _, o := range outputs {
+		if streamingFormats[o.format] {
+			continue
+		}
+		w, err := openOutput(o.file)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		err = printers[o.format](w, results)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s output: %w", o, err)
+		}
+	}
+	return nil
+}
+
+// stream holds the opened streaming outputs during a scan.
+type stream struct {
+	outputs []output
+	writers []io.WriteCloser
+}
+
+// openStreams opens all streaming outputs.
+func openStreams(outputs []output) (*stream, error) {
+	s := &stream{}
+	for _, o := range outputs {
+		if !streamingFormats[o.format] {
+			continue
+		}
+		w, err := openOutput(o.file)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		s.outputs = append(s.outputs, o)
+		s.writers = append(s.writers, w)
+	}
+	return s, nil
+}
+
+// Write writes the results of a single scanned project to all streaming
+// outputs.
+func (s *stream) Write(results internal.TrivyResults) error {
+	for i, o := range s.outputs {
+		if err := printers[o.format](s.writers[i], results); err != nil {
+			return fmt.Errorf("failed to write %s output: %w", o, err)
+		}
+	}
+	return nil
+}
+
+func (s *stream) Close() error {
+	var err error
+	for _, w := range s.writers {
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// needsAllResults returns true if at least one output is written after the
+// scan and needs all results.
+func needsAllResults(outputs []output) bool {
+	for _, o := range outputs {
+		if !streamingFormats[o.format] {
+			return true
+		}
+	}
+	return false
+}
+
+// startsGroup returns true if the project at index i is the first project of
+// a group.
+func startsGroup(results internal.TrivyResults, i int) bool {
+	return results[i].Group != "" && (i == 0 || results[i-1].Group != results[i].Group)
+}
+
+// isGrouped returns true if the results were grouped with --group-by.
+func isGrouped(results internal.TrivyResults) bool {
+	for _, result := range results {
+		if result.Group != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// openOutput returns the given file or stdout if no file is given.
+func openOutput(file string) (io.WriteCloser, error) {
+	if len(file) > 0 {
+		return os.Create(file)
+	}
+	return nopWriteCloser{os.Stdout}, nil
+}
+
+// errWriter remembers the first write error so that printers don't need to
+// check every single write.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
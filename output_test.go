@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions []string
+		defaultFile string
+		want        []output
+		wantErr     string
+	}{
+		{
+			name: "default text",
+			want: []output{{format: "text"}},
+		},
+		{
+			name:        "default file",
+			definitions: []string{"json"},
+			defaultFile: "out.json",
+			want:        []output{{format: "json", file: "out.json"}},
+		},
+		{
+			name:        "several outputs",
+			definitions: []string{"table", " JSON=report.json, html = report.html,", "sarif="},
+			want: []output{
+				{format: "table"},
+				{format: "json", file: "report.json"},
+				{format: "html", file: "report.html"},
+				{format: "sarif"},
+			},
+		},
+		{
+			name:        "file overrides default file",
+			definitions: []string{"json", "csv=findings.csv"},
+			defaultFile: "out.json",
+			want:        []output{{format: "json", file: "out.json"}, {format: "csv", file: "findings.csv"}},
+		},
+		{
+			name:        "unknown format",
+			definitions: []string{"yaml=out.yaml"},
+			wantErr:     `unknown output format "yaml", valid formats are baseimage, csv, html,`,
+		},
+		{
+			name:        "same file",
+			definitions: []string{"json=out", "csv=out"},
+			wantErr:     "outputs json=out and csv=out write to the same file",
+		},
+		{
+			name:        "same default file",
+			definitions: []string{"json", "csv"},
+			defaultFile: "out",
+			wantErr:     "outputs json=out and csv=out write to the same file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := parseOutputs(tt.definitions, tt.defaultFile)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, outputs)
+		})
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("files", func(t *testing.T) {
+		jsonFile := filepath.Join(dir, "report.json")
+		csvFile := filepath.Join(dir, "findings.csv")
+		ndjsonFile := filepath.Join(dir, "findings.ndjson")
+		outputs := []output{{format: "json", file: jsonFile}, {format: "csv", file: csvFile}, {format: "ndjson", file: ndjsonFile}}
+		require.NoError(t, writeOutputs(outputs, loadResults(t)))
+
+		bt, err := os.ReadFile(jsonFile)
+		require.NoError(t, err)
+		report := map[string]any{}
+		require.NoError(t, json.Unmarshal(bt, &report))
+		assert.Len(t, report["projects"], 3)
+		bt, err = os.ReadFile(csvFile)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(bt), "project,namespace,"))
+		assert.NoFileExists(t, ndjsonFile, "streamed outputs are written while scanning")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		err := writeOutputs([]output{{format: "json", file: filepath.Join(dir, "missing", "report.json")}}, loadResults(t))
+		assert.ErrorContains(t, err, "failed to create output file")
+	})
+
+	t.Run("printer error", func(t *testing.T) {
+		setConfig(t, map[string]any{COLUMNS: []string{"cve"}})
+		err := writeOutputs([]output{{format: "csv", file: filepath.Join(dir, "error.csv")}}, loadResults(t))
+		assert.ErrorContains(t, err, "failed to write csv=")
+		assert.ErrorContains(t, err, `unknown column "cve"`)
+	})
+}
+
+func TestPrinters(t *testing.T) {
+	setConfig(t, map[string]any{JUNIT_SEVERITY: "HIGH", NDJSON_PER: "project", COLUMNS: []string{}})
+	for _, format := range outputFormats() {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, printers[format](buf, loadResults(t)))
+			assert.NotEmpty(t, buf.String())
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+func printResultSarif(w io.Writer, results internal.TrivyResults) error {
+	report, err := results.ToSarif(version)
+	if err != nil {
+		return fmt.Errorf("failed to create sarif report: %w", err)
+	}
+	if err := report.PrettyWrite(w); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
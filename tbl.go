@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/aquasecurity/trivy/pkg/types"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -9,7 +10,9 @@ import (
 	"github.com/steffakasid/trivy-scanner/internal"
 )
 
-func printResultTbl(results internal.TrivyResults) {
+func printResultTbl(w io.Writer, results internal.TrivyResults) error {
+	ew := &errWriter{w: w}
+	w = ew
 
 	tw := newLightTableWriter()
 	tw.SetAutoIndex(true)
@@ -21,12 +24,23 @@ func printResultTbl(results internal.TrivyResults) {
 			{Number: 2, WidthMin: 20, WidthMax: 150},
 		})
 		projectTbl.AppendHeader(table.Row{projResult.ProjName, projResult.ProjName})
+		if projResult.Group != "" {
+			projectTbl.AppendRow(table.Row{"Group", projResult.Group})
+		}
 		projectTbl.AppendRow(table.Row{".trivyignore", projResult.Ignore})
 		projectTbl.AppendSeparator()
 
 		summaryTable := newLightTableWriter()
-		summaryTable.AppendHeader(table.Row{"Job", "Scanned Packages", "Vulnerabilities"})
-		summaryTable.AppendRow(table.Row{viper.GetString(internal.JOB_NAME), len(projResult.ReportResult), projResult.Vulnerabilities.Count})
+		summaryTable.AppendHeader(table.Row{"Job", "Scanned Packages", "Vulnerabilities", "Secrets", "Misconfigurations failed", "Licenses", "Disallowed Licenses"})
+		summaryTable.AppendRow(table.Row{
+			viper.GetString(internal.JOB_NAME),
+			len(projResult.ReportResult),
+			projResult.Vulnerabilities.Count,
+			projResult.Vulnerabilities.Secrets,
+			projResult.Vulnerabilities.Misconfigurations.Fail,
+			projResult.Vulnerabilities.Licenses.Count,
+			projResult.Vulnerabilities.Licenses.Disallowed,
+		})
 		projectTbl.AppendRow(table.Row{"Summary", summaryTable.Render()})
 		projectTbl.AppendSeparator()
 
@@ -37,49 +51,162 @@ func printResultTbl(results internal.TrivyResults) {
 		tw.AppendSeparator()
 	}
 
-	fmt.Println(tw.Render())
+	fmt.Fprintln(w, tw.Render())
+	printSecretsTbl(w, results)
+	printLicensesTbl(w, results)
+	return ew.err
+}
+
+// printLicensesTbl prints all forbidden, restricted and disallowed licenses.
+// With -vv or -vvv all license findings are printed.
+func printLicensesTbl(w io.Writer, results internal.TrivyResults) {
+	tw := newLightTableWriter()
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 30},
+		{Number: 2, WidthMax: 50},
+	})
+	tw.AppendHeader(table.Row{"Project", "Package / File", "License", "Category", "Severity", "Disallowed"})
+	count := 0
+	for _, projResult := range results {
+		for _, license := range projResult.Licenses {
+			if !license.IsRestricted() && !viper.GetBool(VV) && !viper.GetBool(VVV) {
+				continue
+			}
+			tw.AppendRow(table.Row{projResult.ProjName, license.Location(), license.Name, license.Category, license.Severity, license.Disallowed})
+			count++
+		}
+	}
+	if count > 0 {
+		tw.AppendFooter(table.Row{"", "", "", "", "Sum", count})
+		fmt.Fprintln(w, tw.Render())
+	}
 }
 
-func printResultDetailsTbl(projTbl table.Writer, res types.Results) {
+func printSecretsTbl(w io.Writer, results internal.TrivyResults) {
+	tw := newLightTableWriter()
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 30},
+		{Number: 2, WidthMax: 50},
+		{Number: 6, WidthMax: 40},
+	})
+	tw.AppendHeader(table.Row{"Project", "Location", "Rule", "Category", "Severity", "Match"})
+	count := 0
+	for _, projResult := range results {
+		for _, secret := range projResult.Secrets {
+			tw.AppendRow(table.Row{projResult.ProjName, secret.Location(), secret.RuleID, secret.Category, secret.Severity, secret.Match})
+			count++
+		}
+	}
+	if count > 0 {
+		tw.AppendFooter(table.Row{"", "", "", "", "Sum", count})
+		fmt.Fprintln(w, tw.Render())
+	}
+}
+
+func printResultDetailsTbl(projTbl table.Writer, res internal.Results) {
+	var prov *internal.Provenance
 	for _, tgt := range res {
-		detailsLvl2 := table.NewWriter()
-		detailsLvl2.SetStyle(table.StyleLight)
-		if (viper.GetBool(VV) || viper.GetBool(VVV)) && len(tgt.Vulnerabilities) > 0 {
-			detailsLvl2.SetColumnConfigs([]table.ColumnConfig{
-				{Number: 1, WidthMax: 30},
-				{Number: 2, WidthMax: 30},
-				{Number: 3, WidthMax: 30},
-				{Number: 4, WidthMax: 40},
-				{Number: 5, WidthMax: 30},
-				{Number: 6, WidthMin: 17, WidthMax: 17},
-				{Number: 7, WidthMin: 15, WidthMax: 15},
-			})
-			headerRow := table.Row{"internal", "ID", "Severity", "Title", "IsFixable"}
-			if viper.GetBool(VVV) {
-				headerRow = append(headerRow, "InstalledVersion", "FixedVersion")
+		if tgt.Provenance != nil && tgt.Provenance != prov {
+			prov = tgt.Provenance
+			projTbl.AppendRow(table.Row{"Source", prov.String()})
+			projTbl.AppendSeparator()
+		}
+		if len(tgt.Vulnerabilities) == 0 && len(tgt.Misconfigurations) == 0 && len(tgt.Secrets) == 0 && len(tgt.Licenses) == 0 {
+			projTbl.AppendRow(table.Row{tgt.Target, "No vulnerabilities found"})
+			projTbl.AppendSeparator()
+			continue
+		}
+		if len(tgt.Vulnerabilities) > 0 {
+			if viper.GetBool(VV) || viper.GetBool(VVV) {
+				projTbl.AppendRow(table.Row{tgt.Target, vulnerabilityDetailsTbl(tgt.Vulnerabilities)})
+			} else {
+				projTbl.AppendRow(table.Row{tgt.Target, vulnerabilitySummaryTbl(tgt.Vulnerabilities)})
 			}
-			detailsLvl2.AppendHeader(headerRow)
-			for _, internal := range tgt.Vulnerabilities {
-				row := table.Row{internal.PkgName, internal.VulnerabilityID, internal.Severity, internal.Title, (internal.FixedVersion != "")}
-				if viper.GetBool(VVV) {
-					row = append(row, internal.InstalledVersion, internal.FixedVersion)
-				}
-				detailsLvl2.AppendRow(row)
+		}
+		if len(tgt.Misconfigurations) > 0 {
+			if viper.GetBool(VV) || viper.GetBool(VVV) {
+				projTbl.AppendRow(table.Row{tgt.Target, misconfigurationDetailsTbl(tgt.Target, tgt.Misconfigurations)})
+			} else {
+				projTbl.AppendRow(table.Row{tgt.Target, misconfigurationSummaryTbl(tgt.Misconfigurations)})
 			}
-			projTbl.AppendRow(table.Row{tgt.Target, detailsLvl2.Render()})
-		} else if len(tgt.Vulnerabilities) == 0 {
-			projTbl.AppendRow(table.Row{tgt.Target, "No vulnerabilities found"})
-		} else {
-			detailsLvl2.AppendHeader(table.Row{"Critical", "High", "Medium", "Low", "Unkown"})
-			crit, hi, med, lo, un := internal.GetSummary(tgt.Vulnerabilities)
-			detailsLvl2.AppendRow(table.Row{crit, hi, med, lo, un})
-			detailsLvl2.AppendFooter(table.Row{"", "", "", "Sum", crit + hi + med + lo + un})
-			projTbl.AppendRow(table.Row{tgt.Target, detailsLvl2.Render()})
+		}
+		if len(tgt.Secrets) > 0 {
+			projTbl.AppendRow(table.Row{tgt.Target, fmt.Sprintf("%d secrets found, see secrets table", len(tgt.Secrets))})
+		}
+		if len(tgt.Licenses) > 0 {
+			projTbl.AppendRow(table.Row{tgt.Target, fmt.Sprintf("%d licenses found, see licenses table", len(tgt.Licenses))})
 		}
 		projTbl.AppendSeparator()
 	}
 }
 
+func vulnerabilityDetailsTbl(vullies []types.DetectedVulnerability) string {
+	detailsLvl2 := newLightTableWriter()
+	detailsLvl2.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 30},
+		{Number: 2, WidthMax: 30},
+		{Number: 3, WidthMax: 30},
+		{Number: 4, WidthMax: 40},
+		{Number: 5, WidthMax: 30},
+		{Number: 6, WidthMin: 17, WidthMax: 17},
+		{Number: 7, WidthMin: 15, WidthMax: 15},
+	})
+	headerRow := table.Row{"internal", "ID", "Severity", "Title", "IsFixable"}
+	if viper.GetBool(VVV) {
+		headerRow = append(headerRow, "InstalledVersion", "FixedVersion")
+	}
+	detailsLvl2.AppendHeader(headerRow)
+	for _, internal := range vullies {
+		row := table.Row{internal.PkgName, internal.VulnerabilityID, internal.Severity, internal.Title, (internal.FixedVersion != "")}
+		if viper.GetBool(VVV) {
+			row = append(row, internal.InstalledVersion, internal.FixedVersion)
+		}
+		detailsLvl2.AppendRow(row)
+	}
+	return detailsLvl2.Render()
+}
+
+func vulnerabilitySummaryTbl(vullies []types.DetectedVulnerability) string {
+	detailsLvl2 := newLightTableWriter()
+	detailsLvl2.AppendHeader(table.Row{"Critical", "High", "Medium", "Low", "Unkown"})
+	crit, hi, med, lo, un := internal.GetSummary(vullies)
+	detailsLvl2.AppendRow(table.Row{crit, hi, med, lo, un})
+	detailsLvl2.AppendFooter(table.Row{"", "", "", "Sum", crit + hi + med + lo + un})
+	return detailsLvl2.Render()
+}
+
+func misconfigurationDetailsTbl(target string, misconfs []types.DetectedMisconfiguration) string {
+	detailsLvl2 := newLightTableWriter()
+	detailsLvl2.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 5, WidthMax: 40},
+		{Number: 6, WidthMax: 40},
+		{Number: 7, WidthMax: 40},
+	})
+	headerRow := table.Row{"ID", "AVD ID", "Severity", "Status", "Message", "Location"}
+	if viper.GetBool(VVV) {
+		headerRow = append(headerRow, "Resolution")
+	}
+	detailsLvl2.AppendHeader(headerRow)
+	for _, misconf := range misconfs {
+		row := table.Row{misconf.ID, misconf.AVDID, misconf.Severity, misconf.Status, misconf.Message, internal.MisconfLocation(target, misconf)}
+		if viper.GetBool(VVV) {
+			row = append(row, misconf.Resolution)
+		}
+		detailsLvl2.AppendRow(row)
+	}
+	pass, fail, exception := internal.GetMisconfSummary(misconfs)
+	detailsLvl2.AppendFooter(table.Row{"", "", "", "Pass/Fail/Exception", fmt.Sprintf("%d/%d/%d", pass, fail, exception)})
+	return detailsLvl2.Render()
+}
+
+func misconfigurationSummaryTbl(misconfs []types.DetectedMisconfiguration) string {
+	detailsLvl2 := newLightTableWriter()
+	detailsLvl2.AppendHeader(table.Row{"Misconf Pass", "Fail", "Exception"})
+	pass, fail, exception := internal.GetMisconfSummary(misconfs)
+	detailsLvl2.AppendRow(table.Row{pass, fail, exception})
+	return detailsLvl2.Render()
+}
+
 func newLightTableWriter() table.Writer {
 	tw := table.NewWriter()
 	tw.SetStyle(table.StyleLight)
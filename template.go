@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+	"github.com/spf13/viper"
+	"github.com/steffakasid/trivy-scanner/internal"
+)
+
+const builtinTextTemplate = "templates/text.tmpl"
+
+// templateData is the model passed to user defined templates.
+type templateData struct {
+	Version   string
+	Results   internal.TrivyResults
+	NameWidth int
+	V         bool
+	VV        bool
+	VVV       bool
+}
+
+type severitySummary struct {
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Unknown  int
+	Total    int
+}
+
+type misconfSummary struct {
+	Pass      int
+	Fail      int
+	Exception int
+}
+
+var templateFuncs = map[string]any{
+	"summary": func(vullies []types.DetectedVulnerability) severitySummary {
+		crit, hi, med, lo, un := internal.GetSummary(vullies)
+		return severitySummary{crit, hi, med, lo, un, crit + hi + med + lo + un}
+	},
+	"misconfSummary": func(misconfs []types.DetectedMisconfiguration) misconfSummary {
+		pass, fail, exception := internal.GetMisconfSummary(misconfs)
+		return misconfSummary{pass, fail, exception}
+	},
+	"misconfLocation": internal.MisconfLocation,
+	"targetWidth":     maxTgtNameLen,
+	"pkgWidth":        maxPckNameLen,
+	"truncate": func(length int, s string) string {
+		return cut(s, length)
+	},
+	"pad": func(length int, s string) string {
+		return padString(s, length)
+	},
+	"padInt": func(length int, num int) string {
+		return padInt(num, length, " ")
+	},
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// printResultTemplate renders the results with the template given by
+// --template. Templates with an .html or .htm extension (optionally followed
+// by .tmpl) are rendered with html/template, all others with text/template.
+// Without --template the built-in text template is used.
+func printResultTemplate(w io.Writer, results internal.TrivyResults) error {
+	return executeTemplate(w, viper.GetString(TEMPLATE), results)
+}
+
+// executeTemplate renders the results with the template file or the built-in
+// text template if file is empty.
+func executeTemplate(w io.Writer, file string, results internal.TrivyResults) error {
+	tmpl, err := parseTemplate(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return tmpl.Execute(w, templateData{
+		Version:   version,
+		Results:   results,
+		NameWidth: maxProjNameLen(results),
+		V:         viper.GetBool(V),
+		VV:        viper.GetBool(VV),
+		VVV:       viper.GetBool(VVV),
+	})
+}
+
+func parseTemplate(file string) (executor, error) {
+	var text []byte
+	var err error
+	if file == "" {
+		file = builtinTextTemplate
+		text, err = templatesFS.ReadFile(builtinTextTemplate)
+	} else {
+		text, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file)
+	switch filepath.Ext(strings.TrimSuffix(strings.ToLower(name), ".tmpl")) {
+	case ".html", ".htm":
+		return htmltemplate.New(name).Funcs(templateFuncs).Parse(string(text))
+	default:
+		return template.New(name).Funcs(templateFuncs).Parse(string(text))
+	}
+}
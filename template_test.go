@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultTemplate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		file     string
+		template string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "helpers",
+			file:     "report.tmpl",
+			template: `{{ range .Results }}{{ pad 5 .ProjName }}|{{ truncate 10 .ProjName }}|{{ padInt 3 .Vulnerabilities.Count }}|{{ join "," .Ignore }}{{ "\n" }}{{ end }}`,
+			want:     "app  |app|  5|CVE-2024-0002\na-...|a-servi...|  0|\nlib  |lib|  0|\n",
+		},
+		{
+			name:     "html is escaped",
+			file:     "report.html.tmpl",
+			template: `{{ range .Results }}{{ range .ReportResult }}{{ range .Vulnerabilities }}<li>{{ .Title }}</li>{{ end }}{{ end }}{{ end }}`,
+			want:     "<li>openssl: a title which is longer than fifty characters and is cut</li><li>busybox: &lt;script&gt; | pipe</li>",
+		},
+		{
+			name:     "unknown field",
+			file:     "report.tmpl",
+			template: `{{ .Projects }}`,
+			wantErr:  "can't evaluate field Projects",
+		},
+		{
+			name:    "missing file",
+			file:    "missing.tmpl",
+			wantErr: "failed to parse template: open ",
+		},
+		{
+			name:     "syntax error",
+			file:     "broken.tmpl",
+			template: `{{ range .Results }}`,
+			wantErr:  "failed to parse template: template: broken.tmpl:1: unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, tt.file)
+			if tt.template != "" {
+				require.NoError(t, os.WriteFile(file, []byte(tt.template), 0o600))
+			}
+			setConfig(t, map[string]any{TEMPLATE: file})
+
+			buf := &bytes.Buffer{}
+			err := printResultTemplate(buf, loadResults(t))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,18 @@
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var tbody = table.tBodies[0];
+      var rows = Array.prototype.slice.call(tbody.rows);
+      var asc = th.dataset.order !== "asc";
+      rows.sort(function (a, b) {
+        var x = a.cells[column].dataset.sort || a.cells[column].textContent.trim();
+        var y = b.cells[column].dataset.sort || b.cells[column].textContent.trim();
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var cmp = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+        return asc ? cmp : -cmp;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+      th.dataset.order = asc ? "asc" : "desc";
+    });
+  });
+});
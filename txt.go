@@ -1,12 +1,11 @@
 package main
 
 import (
-	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
 	"github.com/aquasecurity/trivy/pkg/types"
-	"github.com/spf13/viper"
 	"github.com/steffakasid/trivy-scanner/internal"
 )
 
@@ -15,58 +14,10 @@ const (
 	maxTitleLen = 50
 )
 
-func printResultTxt(results internal.TrivyResults) {
-	maxProjNLen := maxProjNameLen(results)
-	for i, projResult := range results {
-		fmt.Printf("[%s]: %s | Scanned Packages: %s | Vulnerabilities found: %s | .trivyignore: %t\n",
-			padInt(i, 4, "0"),
-			padString(projResult.ProjName, maxProjNLen),
-			padInt(len(projResult.ReportResult), 3, " "),
-			padInt(projResult.Vulnerabilities.Count, 3, " "),
-			(len(projResult.Ignore) > 0))
-		if viper.GetBool(V) || viper.GetBool(VV) || viper.GetBool(VVV) {
-			printResultDetailsTxt(projResult.ReportResult)
-		}
-	}
-}
-
-func printResultDetailsTxt(res types.Results) {
-	maxTgtNLen := maxTgtNameLen(res)
-	lvl1 := strings.Repeat(" ", 2)
-	lvl2 := strings.Repeat(" ", 4)
-	for _, tgt := range res {
-		if viper.GetBool(V) {
-			crit, hi, med, lo, un := internal.GetSummary(tgt.Vulnerabilities)
-			fmt.Printf("%s%s| Critical %s | High %s | Medium %s | Low %s | Unkown %s\n",
-				lvl1,
-				padString(tgt.Target, maxTgtNLen),
-				padInt(crit, 3, " "),
-				padInt(hi, 3, " "),
-				padInt(med, 3, " "),
-				padInt(lo, 3, " "),
-				padInt(un, 3, " "))
-		} else {
-			fmt.Printf("%s%s:\n", lvl1, tgt.Target)
-			maxVuNLen := maxPckNameLen(tgt.Vulnerabilities)
-			if len(tgt.Vulnerabilities) > 0 {
-				// TODO: Add header here
-				for _, vulli := range tgt.Vulnerabilities {
-					fmt.Printf("%s%s | Severity: %s | Title: %s | IsFixable: %t",
-						lvl2,
-						padString(vulli.PkgName, maxVuNLen),
-						vulli.Severity,
-						cut(vulli.Title, maxTitleLen),
-						(vulli.FixedVersion != ""))
-					if viper.GetBool(VVV) {
-						fmt.Printf(" | InstalledVersion: %s | FixedVersion %s", vulli.InstalledVersion, vulli.FixedVersion)
-					}
-					fmt.Println()
-				}
-			} else {
-				fmt.Printf("%s No vulnerabilities found!\n", lvl2)
-			}
-		}
-	}
+// printResultTxt prints the results with the built-in text template, so the
+// text output and template output without --template are always the same.
+func printResultTxt(w io.Writer, results internal.TrivyResults) error {
+	return executeTemplate(w, "", results)
 }
 
 func maxProjNameLen(projs internal.TrivyResults) int {
@@ -82,7 +33,7 @@ func maxProjNameLen(projs internal.TrivyResults) int {
 	return maxLen
 }
 
-func maxTgtNameLen(results types.Results) int {
+func maxTgtNameLen(results internal.Results) int {
 	maxLen := 0
 	for _, res := range results {
 		targetLen := len(res.Target)
@@ -114,7 +65,7 @@ func padString(name string, maxLen int) string {
 		name += strings.Repeat(" ", maxLen-nameLen)
 		return name
 	} else if nameLen > maxLen {
-		name = name[0:maxLen-3] + "..."
+		name = cut(name, maxLen)
 	}
 	return name
 }
@@ -128,9 +79,12 @@ func padInt(num int, length int, padStr string) string {
 	return numStr
 }
 
+// cut shortens t to length and marks it with ... if there is enough space.
 func cut(t string, length int) string {
 	if len(t) <= length {
 		return t
+	} else if length < len("...") {
+		return t[0:max(length, 0)]
 	} else {
 		return t[0:length-3] + "..."
 	}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/steffakasid/trivy-scanner/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintResultTxt(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity string
+		golden    string
+	}{
+		{name: "default", golden: "test/text.golden.txt"},
+		{name: "v", verbosity: V, golden: "test/text-v.golden.txt"},
+		{name: "vv", verbosity: VV, golden: "test/text-vv.golden.txt"},
+		{name: "vvv", verbosity: VVV, golden: "test/text-vvv.golden.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]any{V: false, VV: false, VVV: false}
+			if tt.verbosity != "" {
+				settings[tt.verbosity] = true
+			}
+			setConfig(t, settings)
+			results := loadResults(t)
+			require.NoError(t, results.Order(internal.SortByName, internal.GroupByNamespace))
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, printResultTxt(buf, results))
+			assertGolden(t, tt.golden, buf.String())
+		})
+	}
+}
+
+func TestCut(t *testing.T) {
+	assert.Equal(t, "abcdef", cut("abcdef", 6))
+	assert.Equal(t, "ab...", cut("abcdefgh", 5))
+	assert.Equal(t, "ab", cut("abcdef", 2))
+	assert.Equal(t, "", cut("abcdef", 0))
+}
+
+func TestPadString(t *testing.T) {
+	assert.Equal(t, "abc   ", padString("abc", 6))
+	assert.Equal(t, "ab...", padString("abcdefgh", 5))
+	assert.Equal(t, "ab", padString("abcdef", 2))
+	assert.Equal(t, "", padString("abcdef", -1))
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tmpl, err := template.New("helpers").Funcs(templateFuncs).Parse(`{{ truncate 2 "abcdef" }}|{{ pad 1 "abcdef" }}|{{ truncate 5 "abcdefgh" }}`)
+	require.NoError(t, err)
+	require.NoError(t, tmpl.Execute(buf, nil))
+	assert.Equal(t, "ab|a|ab...", buf.String())
+}